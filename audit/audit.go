@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/jsonl"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// Log appends audit entries to a file, one JSON object per line.
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (l *Log) Record(entry status_groomer.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return jsonl.Append(l.Path, entry)
+}
+
+func Read(r io.Reader) ([]status_groomer.AuditEntry, error) {
+	entries := []status_groomer.AuditEntry{}
+	err := jsonl.Read(r, func(line []byte) error {
+		var entry status_groomer.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Query selects entries by story and by job. Job is either "pipeline/job" or
+// just the job name. Empty fields match everything.
+type Query struct {
+	StoryID string
+	Job     string
+}
+
+func (q Query) Match(entry status_groomer.AuditEntry) bool {
+	if q.StoryID != "" && entry.StoryID != q.StoryID {
+		return false
+	}
+	if q.Job != "" && q.Job != entry.Job && q.Job != entry.Pipeline+"/"+entry.Job {
+		return false
+	}
+	return true
+}
+
+func (q Query) Filter(entries []status_groomer.AuditEntry) []status_groomer.AuditEntry {
+	matched := []status_groomer.AuditEntry{}
+	for _, entry := range entries {
+		if q.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func WriteText(w io.Writer, entries []status_groomer.AuditEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "no matching entries")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tACTION\tSTORY\tTITLE\tGROUP\tRULE\tBUILD\tERROR")
+	for _, e := range entries {
+		build := "-"
+		if e.Pipeline != "" {
+			build = fmt.Sprintf("%s/%s #%d", e.Pipeline, e.Job, e.BuildID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format(time.RFC3339), e.Action, dash(e.StoryID), dash(e.Title), dash(e.Group), dash(e.Rule), build, dash(e.Error))
+	}
+	return tw.Flush()
+}
+
+func WriteJSON(w io.Writer, entries []status_groomer.AuditEntry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
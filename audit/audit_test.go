@@ -0,0 +1,102 @@
+package audit_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/audit"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log", func() {
+	var (
+		dir     string
+		log     *audit.Log
+		created status_groomer.AuditEntry
+		comment status_groomer.AuditEntry
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "audit")
+		Expect(err).NotTo(HaveOccurred())
+		log = &audit.Log{Path: filepath.Join(dir, "audit.jsonl")}
+
+		at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		created = status_groomer.AuditEntry{
+			Time: at, Action: "create", StoryID: "7", Title: "luna has failed", Group: "luna", Rule: "cf-deployment-.*",
+			Pipeline: "cf-deployment", Job: "deploy", BuildID: 12, Payload: map[string]interface{}{"Title": "luna has failed"},
+		}
+		comment = status_groomer.AuditEntry{
+			Time: at.Add(time.Minute), Action: "comment", StoryID: "8", Title: "unit-tests/test has failed",
+			Pipeline: "unit-tests", Job: "test", BuildID: 3,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	read := func() []status_groomer.AuditEntry {
+		f, err := os.Open(log.Path)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		entries, err := audit.Read(f)
+		Expect(err).NotTo(HaveOccurred())
+		return entries
+	}
+
+	It("appends a JSON line per entry", func() {
+		Expect(log.Record(created)).To(Succeed())
+		Expect(log.Record(comment)).To(Succeed())
+
+		data, err := ioutil.ReadFile(log.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(string(data), "\n")).To(Equal(2))
+		Expect(read()).To(Equal([]status_groomer.AuditEntry{created, comment}))
+	})
+
+	It("keeps the entries of earlier runs", func() {
+		Expect(log.Record(created)).To(Succeed())
+		log = &audit.Log{Path: log.Path}
+		Expect(log.Record(comment)).To(Succeed())
+
+		Expect(read()).To(HaveLen(2))
+	})
+
+	It("reports the line of a corrupt entry", func() {
+		_, err := audit.Read(strings.NewReader(`{"action":"create"}` + "\n{not json\n"))
+		Expect(err).To(MatchError(HavePrefix("line 2 - ")))
+	})
+
+	It("queries entries by story or job", func() {
+		entries := []status_groomer.AuditEntry{created, comment}
+
+		Expect(audit.Query{StoryID: "8"}.Filter(entries)).To(Equal([]status_groomer.AuditEntry{comment}))
+		Expect(audit.Query{Job: "cf-deployment/deploy"}.Filter(entries)).To(Equal([]status_groomer.AuditEntry{created}))
+		Expect(audit.Query{Job: "test"}.Filter(entries)).To(Equal([]status_groomer.AuditEntry{comment}))
+		Expect(audit.Query{StoryID: "7", Job: "test"}.Filter(entries)).To(BeEmpty())
+		Expect(audit.Query{}.Filter(entries)).To(HaveLen(2))
+	})
+
+	It("prints entries for humans", func() {
+		var out bytes.Buffer
+		Expect(audit.WriteText(&out, []status_groomer.AuditEntry{created})).To(Succeed())
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(strings.Fields(lines[0])).To(Equal([]string{"TIME", "ACTION", "STORY", "TITLE", "GROUP", "RULE", "BUILD", "ERROR"}))
+		Expect(lines[1]).To(ContainSubstring("create  7      luna has failed  luna   cf-deployment-.*  cf-deployment/deploy #12  -"))
+
+		out.Reset()
+		Expect(audit.WriteText(&out, nil)).To(Succeed())
+		Expect(out.String()).To(Equal("no matching entries\n"))
+	})
+})
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jaresty/concourse-tracker-bot/audit"
+)
+
+func auditCommand(args []string) error {
+	var path string
+	var query audit.Query
+	var format string
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	flags.StringVar(&path, "audit-log", os.Getenv("AUDIT_LOG"), "path to the audit log the bot writes")
+	flags.StringVar(&query.StoryID, "story", "", "only show changes to the story with this ID")
+	flags.StringVar(&query.Job, "job", "", "only show changes caused by builds of this job, as pipeline/job or job")
+	flags.StringVar(&format, "format", "table", "output format: table or json")
+	flags.Parse(args)
+
+	if path == "" {
+		return errors.New("-audit-log is required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := audit.Read(f)
+	if err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	entries = query.Filter(entries)
+
+	switch format {
+	case "table":
+		return audit.WriteText(os.Stdout, entries)
+	case "json":
+		return audit.WriteJSON(os.Stdout, entries)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
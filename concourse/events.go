@@ -0,0 +1,170 @@
+package concourse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event is one server-sent event from a build's event stream.
+type Event struct {
+	ID    string
+	Type  string
+	Data  []byte
+	Retry string
+}
+
+// BuildEvent is the payload of an "event" SSE, e.g. a status change or the
+// end of a step.
+type BuildEvent struct {
+	Event   string          `json:"event"`
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type EventReader struct {
+	scanner *bufio.Scanner
+}
+
+func NewEventReader(r io.Reader) *EventReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &EventReader{scanner: scanner}
+}
+
+// Next returns the next event in the stream, or io.EOF once the stream ends.
+func (r *EventReader) Next() (Event, error) {
+	var event Event
+	var data []string
+	seen := false
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if seen {
+				event.Data = []byte(strings.Join(data, "\n"))
+				return event, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		seen = true
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			event.Retry = value
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	if seen {
+		event.Data = []byte(strings.Join(data, "\n"))
+		return event, nil
+	}
+	return Event{}, io.EOF
+}
+
+type stepOrigin struct {
+	Origin struct {
+		ID string `json:"id"`
+	} `json:"origin"`
+	ExitStatus int `json:"exit_status"`
+}
+
+var stepTypes = []string{"task", "get", "put", "check", "set_pipeline", "load_var"}
+
+// FailingStep returns the name of the first step of a finished build that
+// errored or exited non-zero, or "" if none did.
+func (c ConcourseClient) FailingStep(host string, buildID int) (string, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/api/v1/builds/%d/plan", host, buildID))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var plan interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		return "", err
+	}
+	names := map[string]string{}
+	stepNames(plan, names)
+
+	resp, err = c.client().Get(fmt.Sprintf("%s/api/v1/builds/%d/events", host, buildID))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	events := NewEventReader(resp.Body)
+	for {
+		event, err := events.Next()
+		if err == io.EOF || event.Type == "end" {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if event.Type != "event" {
+			continue
+		}
+
+		var buildEvent BuildEvent
+		if err := json.Unmarshal(event.Data, &buildEvent); err != nil {
+			return "", err
+		}
+
+		var step stepOrigin
+		switch buildEvent.Event {
+		case "finish-task", "finish-get", "finish-put", "error":
+			if err := json.Unmarshal(buildEvent.Data, &step); err != nil {
+				return "", err
+			}
+		default:
+			continue
+		}
+
+		if buildEvent.Event == "error" || step.ExitStatus != 0 {
+			return names[step.Origin.ID], nil
+		}
+	}
+}
+
+// stepNames walks a build plan of arbitrarily nested steps and records the
+// name of every step by plan ID.
+func stepNames(plan interface{}, names map[string]string) {
+	switch node := plan.(type) {
+	case map[string]interface{}:
+		if id, ok := node["id"].(string); ok {
+			for _, stepType := range stepTypes {
+				if step, ok := node[stepType].(map[string]interface{}); ok {
+					if name, ok := step["name"].(string); ok {
+						names[id] = name
+					}
+				}
+			}
+		}
+		for _, child := range node {
+			stepNames(child, names)
+		}
+	case []interface{}:
+		for _, child := range node {
+			stepNames(child, names)
+		}
+	}
+}
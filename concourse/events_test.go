@@ -0,0 +1,143 @@
+package concourse_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	plan = `{
+  "schema": "exec.v2",
+  "plan": {
+    "id": "5f1",
+    "do": [
+      {
+        "id": "5f2",
+        "in_parallel": {
+          "steps": [
+            {"id": "5f3", "get": {"name": "source", "type": "git"}},
+            {"id": "5f4", "get": {"name": "pool", "type": "pool"}}
+          ]
+        }
+      },
+      {
+        "id": "5f6",
+        "on_failure": {
+          "step": {"id": "5f5", "task": {"name": "run-tests"}},
+          "on_failure": {"id": "5f7", "put": {"name": "pool"}}
+        }
+      }
+    ]
+  }
+}`
+
+	failedEvents = `id: 0
+event: event
+data: {"data":{"status":"started","time":1500000000},"event":"status","version":"1.0"}
+
+id: 1
+event: event
+data: {"data":{"origin":{"id":"5f3"},"exit_status":0,"time":1500000001},"event":"finish-get","version":"5.1"}
+
+id: 2
+event: event
+data: {"data":{"origin":{"id":"5f5","source":"stdout"},"payload":"FAIL\n"},"event":"log","version":"5.1"}
+
+id: 3
+event: event
+data: {"data":{"origin":{"id":"5f5"},"exit_status":1,"time":1500000002},"event":"finish-task","version":"4.0"}
+
+id: 4
+event: event
+data: {"data":{"status":"failed","time":1500000003},"event":"status","version":"1.0"}
+
+event: end
+data
+
+`
+)
+
+var _ = Describe("EventReader", func() {
+	It("splits a stream into server-sent events", func() {
+		reader := concourse.NewEventReader(strings.NewReader(": comment\nid: 7\nevent: event\ndata: {\"a\":\ndata: 1}\n\nevent: end\ndata\n\n"))
+
+		event, err := reader.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event).To(Equal(concourse.Event{ID: "7", Type: "event", Data: []byte("{\"a\":\n1}")}))
+
+		event, err = reader.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Type).To(Equal("end"))
+
+		_, err = reader.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+})
+
+var _ = Describe("FailingStep", func() {
+	var (
+		ts     *httptest.Server
+		events string
+	)
+
+	BeforeEach(func() {
+		events = failedEvents
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/builds/42/plan":
+				w.Write([]byte(plan))
+			case "/api/v1/builds/42/events":
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(events))
+			default:
+				w.WriteHeader(http.StatusTeapot)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("names the step that exited non-zero", func() {
+		step, err := concourse.ConcourseClient{}.FailingStep(ts.URL, 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(step).To(Equal("run-tests"))
+	})
+
+	It("names the step that errored", func() {
+		events = `id: 1
+event: event
+data: {"data":{"origin":{"id":"5f4"},"message":"no versions available"},"event":"error","version":"4.1"}
+
+event: end
+data
+
+`
+		step, err := concourse.ConcourseClient{}.FailingStep(ts.URL, 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(step).To(Equal("pool"))
+	})
+
+	It("returns nothing when no step failed", func() {
+		events = "event: end\ndata\n\n"
+
+		step, err := concourse.ConcourseClient{}.FailingStep(ts.URL, 42)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(step).To(BeEmpty())
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the plan is bad", func() {
+			_, err := concourse.ConcourseClient{}.FailingStep(ts.URL, 43)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package concourse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// JobBuilds returns the most recent builds of a job, newest first.
+func (c ConcourseClient) JobBuilds(host, team, pipeline, job string, limit int) ([]status_groomer.Build, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/api/v1/teams/%s/pipelines/%s/jobs/%s/builds?limit=%d", host, team, pipeline, job, limit))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing builds of %s/%s returned %s", pipeline, job, resp.Status)
+	}
+
+	var builds []status_groomer.Build
+	if err := json.NewDecoder(resp.Body).Decode(&builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+type buildResources struct {
+	Inputs []struct {
+		Name    string            `json:"name"`
+		Version map[string]string `json:"version"`
+	} `json:"inputs"`
+}
+
+// BuildInputs returns a fingerprint of the resource versions a build ran
+// with. Two builds with equal fingerprints ran on the same inputs.
+func (c ConcourseClient) BuildInputs(host string, buildID int) (string, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/api/v1/builds/%d/resources", host, buildID))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("listing resources of build %d returned %s", buildID, resp.Status)
+	}
+
+	var resources buildResources
+	if err := json.NewDecoder(resp.Body).Decode(&resources); err != nil {
+		return "", err
+	}
+
+	inputs := []string{}
+	for _, input := range resources.Inputs {
+		version := []string{}
+		for k, v := range input.Version {
+			version = append(version, k+"="+v)
+		}
+		sort.Strings(version)
+		inputs = append(inputs, input.Name+":"+strings.Join(version, ","))
+	}
+	sort.Strings(inputs)
+	return strings.Join(inputs, ";"), nil
+}
@@ -0,0 +1,71 @@
+package concourse_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("build history", func() {
+	var ts *httptest.Server
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.String() {
+			case "/api/v1/teams/main/pipelines/p1/jobs/j1/builds?limit=2":
+				w.Write([]byte(`[
+					{"id": 12, "name": "4", "status": "succeeded", "job_name": "j1", "pipeline_name": "p1"},
+					{"id": 11, "name": "3", "status": "failed", "job_name": "j1", "pipeline_name": "p1"}
+				]`))
+			case "/api/v1/builds/11/resources":
+				w.Write([]byte(`{"inputs": [
+					{"name": "tests", "resource": "tests", "version": {"ref": "abc"}},
+					{"name": "env", "resource": "pool", "version": {"ref": "def", "path": "claimed/env1"}}
+				], "outputs": []}`))
+			case "/api/v1/builds/12/resources":
+				w.Write([]byte(`{"inputs": [
+					{"name": "env", "resource": "pool", "version": {"path": "claimed/env1", "ref": "def"}},
+					{"name": "tests", "resource": "tests", "version": {"ref": "abc"}}
+				], "outputs": [{"name": "env", "version": {"ref": "fed"}}]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("lists a job's most recent builds", func() {
+		builds, err := concourse.ConcourseClient{}.JobBuilds(ts.URL, "main", "p1", "j1", 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(builds).To(Equal([]status_groomer.Build{
+			{ID: 12, Name: "4", Status: "succeeded", JobName: "j1", PipelineName: "p1"},
+			{ID: 11, Name: "3", Status: "failed", JobName: "j1", PipelineName: "p1"},
+		}))
+	})
+
+	It("fingerprints builds on the same inputs identically regardless of order", func() {
+		failed, err := concourse.ConcourseClient{}.BuildInputs(ts.URL, 11)
+		Expect(err).NotTo(HaveOccurred())
+		passed, err := concourse.ConcourseClient{}.BuildInputs(ts.URL, 12)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(failed).To(Equal("env:path=claimed/env1,ref=def;tests:ref=abc"))
+		Expect(passed).To(Equal(failed))
+	})
+
+	It("returns an error for unknown jobs and builds", func() {
+		_, err := concourse.ConcourseClient{}.JobBuilds(ts.URL, "main", "p1", "nope", 2)
+		Expect(err).To(MatchError("listing builds of p1/nope returned 404 Not Found"))
+
+		_, err = concourse.ConcourseClient{}.BuildInputs(ts.URL, 99)
+		Expect(err).To(MatchError("listing resources of build 99 returned 404 Not Found"))
+	})
+})
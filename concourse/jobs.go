@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type Pipeline struct {
@@ -18,20 +19,48 @@ type Group struct {
 	Jobs []string `json:"jobs"`
 }
 
-type ConcourseClient struct{}
+// ConcourseClient reads pipelines, jobs and builds from the Concourse API.
+// Without an HTTPClient each request gives up after 30 seconds.
+type ConcourseClient struct {
+	HTTPClient *http.Client
+}
 
-func (c ConcourseClient) GetJobURLs(host string, team string) ([]string, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/api/v1/teams/%s/pipelines", host, team))
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+func (c ConcourseClient) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultClient
+}
+
+func getPipelines(client *http.Client, host string, team string) ([]Pipeline, error) {
+	resp, err := client.Get(fmt.Sprintf("%s/api/v1/teams/%s/pipelines", host, team))
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	var pipelines []Pipeline
 	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
-		return []string{}, err
+		return nil, err
 	}
+	return pipelines, nil
+}
 
-	urls := []string{}
+// PipelineJob is a job of an unpaused pipeline that the bot watches.
+type PipelineJob struct {
+	Pipeline string
+	Job      string
+}
+
+func (c ConcourseClient) GetJobs(host string, team string) ([]PipelineJob, error) {
+	pipelines, err := getPipelines(c.client(), host, team)
+	if err != nil {
+		return []PipelineJob{}, err
+	}
+
+	jobs := []PipelineJob{}
 	for _, pipeline := range pipelines {
 		if pipeline.Paused {
 			continue
@@ -39,12 +68,68 @@ func (c ConcourseClient) GetJobURLs(host string, team string) ([]string, error)
 
 		for _, group := range pipeline.Groups {
 			for _, job := range group.Jobs {
-				urls = append(urls,
-					fmt.Sprintf("%s/api/v1/teams/%s/pipelines/%s/jobs/%s", host, team, pipeline.Name, job),
-				)
+				jobs = append(jobs, PipelineJob{Pipeline: pipeline.Name, Job: job})
 			}
 		}
 	}
 
+	return jobs, nil
+}
+
+func (c ConcourseClient) GetJobURLs(host string, team string) ([]string, error) {
+	jobs, err := c.GetJobs(host, team)
+	if err != nil {
+		return []string{}, err
+	}
+
+	urls := []string{}
+	for _, job := range jobs {
+		urls = append(urls,
+			fmt.Sprintf("%s/api/v1/teams/%s/pipelines/%s/jobs/%s", host, team, job.Pipeline, job.Job),
+		)
+	}
+
 	return urls, nil
 }
+
+// PipelineGroups maps each watched "<pipeline>/<job>" to "<pipeline>/<group>"
+// for the first group of its pipeline that lists it. A group that lists every
+// job of a pipeline with other groups, such as "all", is only used for jobs in
+// no other group.
+func (c ConcourseClient) PipelineGroups(host string, team string) (map[string]string, error) {
+	pipelines, err := getPipelines(c.client(), host, team)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]string{}
+	for _, pipeline := range pipelines {
+		if pipeline.Paused {
+			continue
+		}
+
+		jobs := map[string]bool{}
+		for _, group := range pipeline.Groups {
+			for _, job := range group.Jobs {
+				jobs[job] = true
+			}
+		}
+		catchAll := func(group Group) bool {
+			return len(pipeline.Groups) > 1 && len(group.Jobs) == len(jobs)
+		}
+		for _, pass := range []bool{false, true} {
+			for _, group := range pipeline.Groups {
+				if catchAll(group) != pass {
+					continue
+				}
+				for _, job := range group.Jobs {
+					key := pipeline.Name + "/" + job
+					if _, ok := groups[key]; !ok {
+						groups[key] = pipeline.Name + "/" + group.Name
+					}
+				}
+			}
+		}
+	}
+	return groups, nil
+}
@@ -123,3 +123,67 @@ var _ = Describe("GetJobURLs", func() {
 		})
 	})
 })
+
+var _ = Describe("GetJobs", func() {
+	It("names the jobs of unpaused pipelines", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(pipelines))
+		}))
+		defer ts.Close()
+
+		jobs, err := concourse.ConcourseClient{}.GetJobs(ts.URL, "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(6))
+		Expect(jobs[0]).To(Equal(concourse.PipelineJob{Pipeline: "p1", Job: "g1j1"}))
+		Expect(jobs[5]).To(Equal(concourse.PipelineJob{Pipeline: "p2", Job: "g1j2"}))
+	})
+})
+
+var _ = Describe("PipelineGroups", func() {
+	serve := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+	}
+
+	It("maps the jobs of unpaused pipelines to their pipeline group", func() {
+		ts := serve(pipelines)
+		defer ts.Close()
+
+		groups, err := concourse.ConcourseClient{}.PipelineGroups(ts.URL, "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal(map[string]string{
+			"p1/g1j1": "p1/g1",
+			"p1/g1j2": "p1/g1",
+			"p1/g2j1": "p1/g2",
+			"p1/g2j2": "p1/g2",
+			"p2/g1j1": "p2/g1",
+			"p2/g1j2": "p2/g1",
+		}))
+	})
+
+	It("prefers any other group over one listing every job", func() {
+		ts := serve(`[{"name": "p1", "groups": [
+			{"name": "all", "jobs": ["unit", "deploy", "smoke"]},
+			{"name": "test", "jobs": ["unit"]},
+			{"name": "release", "jobs": ["deploy", "unit"]}
+		]}]`)
+		defer ts.Close()
+
+		groups, err := concourse.ConcourseClient{}.PipelineGroups(ts.URL, "main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal(map[string]string{
+			"p1/unit":   "p1/test",
+			"p1/deploy": "p1/release",
+			"p1/smoke":  "p1/all",
+		}))
+	})
+
+	It("returns an error when the json is bad", func() {
+		ts := serve("%%%%")
+		defer ts.Close()
+
+		_, err := concourse.ConcourseClient{}.PipelineGroups(ts.URL, "main")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,257 @@
+package concourse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+)
+
+type BuildSummary struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	TeamName     string `json:"team_name"`
+	PipelineName string `json:"pipeline_name"`
+	JobName      string `json:"job_name"`
+}
+
+// Watcher follows the event streams of running builds of watched jobs and
+// reports each build as soon as its final status arrives.
+type Watcher struct {
+	Host           string
+	Team           string
+	HTTPClient     *http.Client
+	Interval       time.Duration
+	ReconnectDelay time.Duration
+	MaxReconnects  int
+	Finished       func(BuildSummary)
+	Log            logging.Logger
+
+	mu        sync.Mutex
+	streaming map[int]bool
+}
+
+// Run looks for newly started builds every interval until stop is closed.
+func (w *Watcher) Run(stop <-chan struct{}) {
+	interval := w.Interval
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+
+	for {
+		if err := w.Watch(); err != nil {
+			w.Log.Warn("failed to list running builds", "error", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Watch starts streaming every running build of a watched job that is not
+// already being streamed.
+func (w *Watcher) Watch() error {
+	watched, err := w.watchedJobs()
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client().Get(fmt.Sprintf("%s/api/v1/teams/%s/builds", w.Host, w.Team))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("listing builds returned %s", resp.Status)
+	}
+
+	var builds []BuildSummary
+	if err := json.NewDecoder(resp.Body).Decode(&builds); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.streaming == nil {
+		w.streaming = map[int]bool{}
+	}
+	for _, build := range builds {
+		if build.Status != "started" && build.Status != "pending" {
+			continue
+		}
+		if !watched[build.PipelineName+"/"+build.JobName] || w.streaming[build.ID] {
+			continue
+		}
+		w.streaming[build.ID] = true
+		go func(build BuildSummary) {
+			if err := w.Stream(build); err != nil {
+				w.Log.Warn("stopped following build", "pipeline", build.PipelineName, "job", build.JobName, "build_id", build.ID, "error", err)
+			}
+			w.mu.Lock()
+			delete(w.streaming, build.ID)
+			w.mu.Unlock()
+		}(build)
+	}
+	return nil
+}
+
+// Stream follows a build's events until its final status, reconnecting from
+// the last seen event when the stream drops.
+func (w *Watcher) Stream(build BuildSummary) error {
+	delay := w.ReconnectDelay
+	if delay == 0 {
+		delay = time.Second
+	}
+	maxReconnects := w.MaxReconnects
+	if maxReconnects == 0 {
+		maxReconnects = 10
+	}
+
+	lastEventID := ""
+	failures := 0
+	for {
+		status, id, retry, err := w.follow(build.ID, lastEventID)
+		if status != "" {
+			build.Status = status
+			w.Finished(build)
+			return nil
+		}
+		if err == nil {
+			return w.reportFinished(build.ID)
+		}
+
+		if id != lastEventID {
+			lastEventID = id
+			failures = 0
+		}
+		failures++
+		if failures > maxReconnects {
+			return err
+		}
+		if retry > 0 {
+			delay = retry
+		}
+		time.Sleep(delay)
+	}
+}
+
+// follow reads a build's event stream and returns its final status, or the
+// last event ID and server retry hint when the stream ends early. A nil
+// error without a status means the build ended without reporting one.
+func (w *Watcher) follow(buildID int, lastEventID string) (string, string, time.Duration, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/builds/%d/events", w.Host, buildID), nil)
+	if err != nil {
+		return "", lastEventID, 0, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := w.client().Do(req)
+	if err != nil {
+		return "", lastEventID, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", lastEventID, 0, fmt.Errorf("event stream returned %s", resp.Status)
+	}
+
+	var retry time.Duration
+	events := NewEventReader(resp.Body)
+	for {
+		event, err := events.Next()
+		if err == io.EOF {
+			return "", lastEventID, retry, fmt.Errorf("event stream closed")
+		}
+		if err != nil {
+			return "", lastEventID, retry, err
+		}
+		if event.ID != "" {
+			lastEventID = event.ID
+		}
+		if ms, err := strconv.Atoi(event.Retry); err == nil {
+			retry = time.Duration(ms) * time.Millisecond
+		}
+
+		switch event.Type {
+		case "end":
+			return "", lastEventID, retry, nil
+		case "event":
+			if status := finalStatus(event.Data); status != "" {
+				return status, lastEventID, retry, nil
+			}
+		}
+	}
+}
+
+func (w *Watcher) reportFinished(buildID int) error {
+	resp, err := w.client().Get(fmt.Sprintf("%s/api/v1/builds/%d", w.Host, buildID))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var build BuildSummary
+	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+		return err
+	}
+	switch build.Status {
+	case "succeeded", "failed", "errored", "aborted":
+		w.Finished(build)
+	}
+	return nil
+}
+
+func finalStatus(data []byte) string {
+	var buildEvent BuildEvent
+	if err := json.Unmarshal(data, &buildEvent); err != nil || buildEvent.Event != "status" {
+		return ""
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(buildEvent.Data, &status); err != nil {
+		return ""
+	}
+	switch status.Status {
+	case "succeeded", "failed", "errored", "aborted":
+		return status.Status
+	}
+	return ""
+}
+
+func (w *Watcher) watchedJobs() (map[string]bool, error) {
+	pipelines, err := getPipelines(w.client(), w.Host, w.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	watched := map[string]bool{}
+	for _, pipeline := range pipelines {
+		if pipeline.Paused {
+			continue
+		}
+		for _, group := range pipeline.Groups {
+			for _, job := range group.Jobs {
+				watched[pipeline.Name+"/"+job] = true
+			}
+		}
+	}
+	return watched, nil
+}
+
+func (w *Watcher) client() *http.Client {
+	if w.HTTPClient != nil {
+		return w.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,128 @@
+package concourse_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		ts           *httptest.Server
+		watcher      *concourse.Watcher
+		mu           sync.Mutex
+		finished     []concourse.BuildSummary
+		lastEventIDs []string
+		dropAfter    int
+		streams      int
+	)
+
+	eventLines := []string{
+		`id: 0` + "\nevent: event\n" + `data: {"data":{"status":"started"},"event":"status","version":"1.0"}` + "\n\n",
+		`id: 1` + "\nevent: event\n" + `data: {"data":{"origin":{"id":"5f5"},"exit_status":1},"event":"finish-task","version":"4.0"}` + "\n\n",
+		`id: 2` + "\nevent: event\n" + `data: {"data":{"status":"failed"},"event":"status","version":"1.0"}` + "\n\n",
+		"event: end\ndata\n\n",
+	}
+
+	Finished := func() []concourse.BuildSummary {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]concourse.BuildSummary{}, finished...)
+	}
+
+	BeforeEach(func() {
+		finished = nil
+		lastEventIDs = nil
+		dropAfter = 0
+		streams = 0
+
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/teams/main/pipelines":
+				w.Write([]byte(`[
+					{"name": "p1", "groups": [{"name": "g1", "jobs": ["j1"]}]},
+					{"name": "p2", "paused": true, "groups": [{"name": "g1", "jobs": ["j1"]}]}
+				]`))
+			case "/api/v1/teams/main/builds":
+				w.Write([]byte(`[
+					{"id": 42, "name": "7", "status": "started", "team_name": "main", "pipeline_name": "p1", "job_name": "j1"},
+					{"id": 43, "name": "1", "status": "started", "team_name": "main", "pipeline_name": "p2", "job_name": "j1"},
+					{"id": 41, "name": "6", "status": "failed", "team_name": "main", "pipeline_name": "p1", "job_name": "j1"},
+					{"id": 40, "name": "1", "status": "started", "team_name": "main", "pipeline_name": "p1", "job_name": "unlisted"}
+				]`))
+			case "/api/v1/builds/42/events":
+				mu.Lock()
+				lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+				streams++
+				drop := streams == 1 && dropAfter > 0
+				mu.Unlock()
+
+				start := 0
+				fmt.Sscan(r.Header.Get("Last-Event-ID"), &start)
+				if r.Header.Get("Last-Event-ID") != "" {
+					start++
+				}
+				for i, line := range eventLines[start:] {
+					if drop && i == dropAfter {
+						return
+					}
+					w.Write([]byte(line))
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		watcher = &concourse.Watcher{
+			Host:           ts.URL,
+			Team:           "main",
+			ReconnectDelay: time.Millisecond,
+			Log:            logging.Discard,
+			Finished: func(build concourse.BuildSummary) {
+				mu.Lock()
+				defer mu.Unlock()
+				finished = append(finished, build)
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("reports running builds of watched jobs as soon as they finish", func() {
+		Expect(watcher.Watch()).To(Succeed())
+
+		Eventually(Finished).Should(Equal([]concourse.BuildSummary{
+			{ID: 42, Name: "7", Status: "failed", TeamName: "main", PipelineName: "p1", JobName: "j1"},
+		}))
+	})
+
+	It("resumes a dropped stream from the last event it saw", func() {
+		dropAfter = 2
+
+		Expect(watcher.Watch()).To(Succeed())
+
+		Eventually(Finished).Should(HaveLen(1))
+		Expect(Finished()[0].Status).To(Equal("failed"))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(lastEventIDs).To(Equal([]string{"", "1"}))
+	})
+
+	It("gives up after too many failed reconnects", func() {
+		watcher.MaxReconnects = 2
+		err := watcher.Stream(concourse.BuildSummary{ID: 99})
+
+		Expect(err).To(MatchError("event stream returned 404 Not Found"))
+		Expect(Finished()).To(BeEmpty())
+	})
+})
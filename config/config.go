@@ -0,0 +1,38 @@
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/jaresty/concourse-tracker-bot/notifier"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"gopkg.in/yaml.v2"
+)
+
+// Config holds the bot's optional settings. Grouping rules live in their own
+// file so that they can be shared with pipeline owners.
+type Config struct {
+	Notifications struct {
+		Slack    notifier.Slack         `yaml:"slack"`
+		Webhooks notifier.WebhookConfig `yaml:"webhooks"`
+	} `yaml:"notifications"`
+	Flakes     status_groomer.FlakeDetection `yaml:"flakes"`
+	Escalation status_groomer.Escalation     `yaml:"escalation"`
+	Storm      status_groomer.StormBreaker   `yaml:"storm"`
+}
+
+// Load reads the config file at path. An empty path yields the zero Config.
+func Load(path string) (Config, error) {
+	var c Config
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
@@ -0,0 +1,164 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/config"
+	"github.com/jaresty/concourse-tracker-bot/notifier"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "config")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(contents string) string {
+		path := filepath.Join(dir, "config.yml")
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	It("reads the Slack notification settings", func() {
+		c, err := config.Load(write(`
+notifications:
+  slack:
+    webhook_url: https://hooks.slack.com/services/default
+    channel: "#ci"
+    groups:
+      luna:
+        channel: "#luna"
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Notifications.Slack.Channel).To(Equal(notifier.Channel{
+			WebhookURL: "https://hooks.slack.com/services/default",
+			Channel:    "#ci",
+		}))
+		Expect(c.Notifications.Slack.Groups).To(Equal(map[string]notifier.Channel{
+			"luna": {Channel: "#luna"},
+		}))
+	})
+
+	It("reads the outbound webhook settings", func() {
+		c, err := config.Load(write(`
+notifications:
+  webhooks:
+    queue_dir: /var/vcap/data/webhooks
+    max_queued: 50
+    endpoints:
+    - url: https://dashboard.example.com/events
+      secret: shh
+      events: [story_created, story_resolved]
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Notifications.Webhooks).To(Equal(notifier.WebhookConfig{
+			QueueDir:  "/var/vcap/data/webhooks",
+			MaxQueued: 50,
+			Endpoints: []notifier.Endpoint{{
+				URL:    "https://dashboard.example.com/events",
+				Secret: "shh",
+				Events: []string{"story_created", "story_resolved"},
+			}},
+		}))
+	})
+
+	It("reads the flake detection settings", func() {
+		c, err := config.Load(write(`
+flakes:
+  window: 20
+  label: flaky-test
+  consolidate: true
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Flakes).To(Equal(status_groomer.FlakeDetection{
+			Window:      20,
+			Label:       "flaky-test",
+			Consolidate: true,
+		}))
+	})
+
+	It("reads the escalation rules", func() {
+		c, err := config.Load(write(`
+escalation:
+  default:
+  - after: 24h
+    label: escalated
+  groups:
+    luna:
+    - after: 12h
+      label: escalated
+      move_to_top: true
+      issue_type: bug
+      notify: ["<@U123>"]
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Escalation).To(Equal(status_groomer.Escalation{
+			Default: []status_groomer.EscalationRule{
+				{After: status_groomer.Duration(24 * time.Hour), Label: "escalated"},
+			},
+			Groups: map[string][]status_groomer.EscalationRule{
+				"luna": {{
+					After:     status_groomer.Duration(12 * time.Hour),
+					Label:     "escalated",
+					MoveToTop: true,
+					IssueType: "bug",
+					Notify:    []string{"<@U123>"},
+				}},
+			},
+		}))
+	})
+
+	It("rejects escalation thresholds that are not durations", func() {
+		_, err := config.Load(write(`
+escalation:
+  default:
+  - after: a day
+`))
+		Expect(err).To(MatchError(ContainSubstring("invalid duration")))
+	})
+
+	It("reads the storm breaker settings", func() {
+		c, err := config.Load(write(`
+storm:
+  groups: 5
+  window: 10m
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Storm).To(Equal(status_groomer.StormBreaker{
+			Groups: 5,
+			Window: status_groomer.Duration(10 * time.Minute),
+		}))
+	})
+
+	It("returns an empty config without a path", func() {
+		c, err := config.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(config.Config{}))
+	})
+
+	Context("failure cases", func() {
+		It("rejects unknown settings", func() {
+			_, err := config.Load(write("notifications:\n  slak: {}\n"))
+			Expect(err).To(MatchError(ContainSubstring("field slak not found")))
+		})
+
+		It("returns an error when the file is missing", func() {
+			_, err := config.Load(filepath.Join(dir, "missing.yml"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+)
+
+const Ungrouped = "ungrouped"
+
+type Job struct {
+	Pipeline string `json:"pipeline"`
+	Job      string `json:"job"`
+	// Group is Ungrouped for jobs no rule matches.
+	Group    string   `json:"group"`
+	Patterns []string `json:"patterns,omitempty"`
+	// Ambiguous is set when rules of more than one group match the job.
+	Ambiguous bool `json:"ambiguous,omitempty"`
+}
+
+type Rule struct {
+	Group   string `json:"group"`
+	Pattern string `json:"pattern"`
+}
+
+// Report shows which group each job's failures are filed under and which
+// rules match no job at all.
+type Report struct {
+	Jobs        []Job  `json:"jobs"`
+	UnusedRules []Rule `json:"unused_rules"`
+}
+
+// Grouper tells which group the failures of a job are filed under, as
+// *status_groomer.Groomer does.
+type Grouper interface {
+	GroupRule(pipeline, job string) (string, string)
+	AutoGroupOf(pipeline, job string) string
+}
+
+func New(groups map[string][]string, jobs []concourse.PipelineJob, grouper Grouper) Report {
+	report := Report{Jobs: []Job{}, UnusedRules: []Rule{}}
+	used := map[Rule]bool{}
+	seen := map[concourse.PipelineJob]bool{}
+
+	for _, j := range jobs {
+		if seen[j] {
+			continue
+		}
+		seen[j] = true
+
+		job := Job{Pipeline: j.Pipeline, Job: j.Job, Group: Ungrouped}
+		matches := parser.Matches(groups, fmt.Sprintf("%s-%s", j.Pipeline, j.Job))
+		if auto := grouper.AutoGroupOf(j.Pipeline, j.Job); auto != "" {
+			matches = append(matches, parser.Matches(groups, auto)...)
+		}
+		matchedGroups := map[string]bool{}
+		for _, m := range matches {
+			used[Rule{Group: m.Group, Pattern: m.Pattern}] = true
+			matchedGroups[m.Group] = true
+			job.Patterns = append(job.Patterns, m.Pattern)
+		}
+		if pattern, group := grouper.GroupRule(j.Pipeline, j.Job); group != "" {
+			job.Group = group
+			matchedGroups[group] = true
+			if len(job.Patterns) == 0 {
+				job.Patterns = []string{pattern}
+			}
+		}
+		job.Ambiguous = len(matchedGroups) > 1
+		report.Jobs = append(report.Jobs, job)
+	}
+
+	for group, patterns := range groups {
+		for _, pattern := range patterns {
+			if rule := (Rule{Group: group, Pattern: pattern}); !used[rule] {
+				report.UnusedRules = append(report.UnusedRules, rule)
+			}
+		}
+	}
+
+	sort.Slice(report.Jobs, func(i, k int) bool {
+		a, b := report.Jobs[i], report.Jobs[k]
+		if a.Pipeline != b.Pipeline {
+			return a.Pipeline < b.Pipeline
+		}
+		return a.Job < b.Job
+	})
+	sort.Slice(report.UnusedRules, func(i, k int) bool {
+		a, b := report.UnusedRules[i], report.UnusedRules[k]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Pattern < b.Pattern
+	})
+	return report
+}
+
+func (j Job) group() string {
+	if j.Ambiguous {
+		return j.Group + " (ambiguous)"
+	}
+	return j.Group
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PIPELINE\tJOB\tGROUP\tRULE")
+	for _, job := range r.Jobs {
+		patterns := "-"
+		if len(job.Patterns) > 0 {
+			patterns = strings.Join(job.Patterns, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", job.Pipeline, job.Job, job.group(), patterns)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(r.UnusedRules) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nrules that match no job:")
+	fmt.Fprintln(tw, "GROUP\tRULE")
+	for _, rule := range r.UnusedRules {
+		fmt.Fprintf(tw, "%s\t%s\n", rule.Group, rule.Pattern)
+	}
+	return tw.Flush()
+}
+
+func (r Report) WriteMarkdown(w io.Writer) error {
+	lines := []string{
+		"| Pipeline | Job | Group | Rule |",
+		"| --- | --- | --- | --- |",
+	}
+	for _, job := range r.Jobs {
+		patterns := []string{}
+		for _, p := range job.Patterns {
+			patterns = append(patterns, markdownCode(p))
+		}
+		lines = append(lines, fmt.Sprintf("| %s | %s | %s | %s |", job.Pipeline, job.Job, job.group(), strings.Join(patterns, ", ")))
+	}
+
+	if len(r.UnusedRules) > 0 {
+		lines = append(lines, "", "Rules that match no job:", "")
+		for _, rule := range r.UnusedRules {
+			lines = append(lines, fmt.Sprintf("- %s: %s", rule.Group, markdownCode(rule.Pattern)))
+		}
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func markdownCode(s string) string {
+	return "`" + strings.Replace(s, "|", "\\|", -1) + "`"
+}
@@ -0,0 +1,111 @@
+package coverage_test
+
+import (
+	"bytes"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/coverage"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	var (
+		groups  map[string][]string
+		jobs    []concourse.PipelineJob
+		groomer *status_groomer.Groomer
+		report  coverage.Report
+	)
+
+	BeforeEach(func() {
+		groups = map[string][]string{
+			"luna":   {"cf-deployment-.*-fresh", "cf-deployment-fresh-.*"},
+			"snitch": {"cf-deployment-.*-lite", "cf-deployment-lite-.*"},
+			"all":    {"cf-deployment-experimental-.*"},
+		}
+		jobs = []concourse.PipelineJob{
+			{Pipeline: "cf-deployment", Job: "deploy-fresh"},
+			{Pipeline: "cf-deployment", Job: "experimental-lite"},
+			{Pipeline: "cf-deployment", Job: "unit-tests"},
+			{Pipeline: "cf-deployment", Job: "deploy-fresh"},
+		}
+		groomer = &status_groomer.Groomer{GroupingStrategy: parser.Parse(groups)}
+		report = coverage.New(groups, jobs, groomer)
+	})
+
+	It("maps each job to the group rule it matches", func() {
+		Expect(report.Jobs).To(Equal([]coverage.Job{
+			{Pipeline: "cf-deployment", Job: "deploy-fresh", Group: "luna", Patterns: []string{"cf-deployment-.*-fresh"}},
+			{Pipeline: "cf-deployment", Job: "experimental-lite", Group: "all", Patterns: []string{"cf-deployment-experimental-.*", "cf-deployment-.*-lite"}, Ambiguous: true},
+			{Pipeline: "cf-deployment", Job: "unit-tests", Group: coverage.Ungrouped},
+		}))
+	})
+
+	It("lists rules that match no job", func() {
+		Expect(report.UnusedRules).To(Equal([]coverage.Rule{
+			{Group: "luna", Pattern: "cf-deployment-fresh-.*"},
+			{Group: "snitch", Pattern: "cf-deployment-lite-.*"},
+		}))
+	})
+
+	It("prints a table", func() {
+		out := &bytes.Buffer{}
+		Expect(report.WriteTable(out)).To(Succeed())
+		Expect(out.String()).To(Equal(`PIPELINE       JOB                GROUP            RULE
+cf-deployment  deploy-fresh       luna             cf-deployment-.*-fresh
+cf-deployment  experimental-lite  all (ambiguous)  cf-deployment-experimental-.*, cf-deployment-.*-lite
+cf-deployment  unit-tests         ungrouped        -
+
+rules that match no job:
+GROUP   RULE
+luna    cf-deployment-fresh-.*
+snitch  cf-deployment-lite-.*
+`))
+	})
+
+	It("prints Markdown for pull requests", func() {
+		out := &bytes.Buffer{}
+		Expect(report.WriteMarkdown(out)).To(Succeed())
+		Expect(out.String()).To(Equal("| Pipeline | Job | Group | Rule |\n" +
+			"| --- | --- | --- | --- |\n" +
+			"| cf-deployment | deploy-fresh | luna | `cf-deployment-.*-fresh` |\n" +
+			"| cf-deployment | experimental-lite | all (ambiguous) | `cf-deployment-experimental-.*`, `cf-deployment-.*-lite` |\n" +
+			"| cf-deployment | unit-tests | ungrouped |  |\n" +
+			"\n" +
+			"Rules that match no job:\n" +
+			"\n" +
+			"- luna: `cf-deployment-fresh-.*`\n" +
+			"- snitch: `cf-deployment-lite-.*`\n"))
+	})
+
+	It("shows the Concourse group of jobs no rule matches when auto grouping", func() {
+		grouper := new(fakes.FakePipelineGrouper)
+		grouper.PipelineGroupsReturns(map[string]string{
+			"cf-deployment/unit-tests":        "cf-deployment/units",
+			"cf-deployment/experimental-lite": "cf-deployment/experiments",
+		}, nil)
+		groomer.AutoGroup = true
+		groomer.Concourse = struct {
+			*fakes.FakeConcourseClient
+			*fakes.FakePipelineGrouper
+		}{new(fakes.FakeConcourseClient), grouper}
+		Expect(groomer.LoadAutoGroups()).To(Succeed())
+
+		report = coverage.New(groups, jobs, groomer)
+		Expect(report.Jobs[1].Group).To(Equal("all"))
+		Expect(report.Jobs[2]).To(Equal(coverage.Job{
+			Pipeline: "cf-deployment", Job: "unit-tests", Group: "cf-deployment/units", Patterns: []string{"concourse"},
+		}))
+	})
+
+	It("prints JSON", func() {
+		out := &bytes.Buffer{}
+		Expect(report.WriteJSON(out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`"group": "ungrouped"`))
+		Expect(out.String()).To(ContainSubstring(`"unused_rules": [`))
+	})
+})
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/coverage"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+func coverageCommand(args []string) error {
+	var opts options
+	var format string
+	flags := flag.NewFlagSet("coverage", flag.ExitOnError)
+	opts.register(flags)
+	flags.StringVar(&format, "format", "table", "output format: table, json or markdown")
+	flags.Parse(args)
+
+	autoGroup, err := opts.autoGroup()
+	if err != nil {
+		return err
+	}
+	if opts.groupConfigFile == "" && !autoGroup {
+		return errors.New("-group-config-file is required unless -grouping is auto")
+	}
+	log, err := opts.logger()
+	if err != nil {
+		return err
+	}
+	groups, err := opts.groups(log)
+	if err != nil {
+		return err
+	}
+
+	groomer := &status_groomer.Groomer{
+		GroupingStrategy: parser.Parse(groups),
+		Host:             os.Getenv("CONCOURSE_HOST"),
+		Team:             os.Getenv("CONCOURSE_TEAM"),
+		Concourse:        concourse.ConcourseClient{},
+		AutoGroup:        autoGroup,
+	}
+	if err := groomer.LoadAutoGroups(); err != nil {
+		return err
+	}
+	jobs, err := concourse.ConcourseClient{}.GetJobs(groomer.Host, groomer.Team)
+	if err != nil {
+		return err
+	}
+
+	report := coverage.New(groups, jobs, groomer)
+	switch format {
+	case "table":
+		return report.WriteTable(os.Stdout)
+	case "json":
+		return report.WriteJSON(os.Stdout)
+	case "markdown":
+		return report.WriteMarkdown(os.Stdout)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+func explainCommand(args []string) error {
+	var opts options
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	opts.register(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return errors.New("usage: concourse-tracker-bot explain [flags] <pipeline> <job>")
+	}
+	pipeline, job := flags.Arg(0), flags.Arg(1)
+
+	log, err := opts.logger()
+	if err != nil {
+		return err
+	}
+	groups, err := opts.groups(log)
+	if err != nil {
+		return err
+	}
+
+	autoGroup, err := opts.autoGroup()
+	if err != nil {
+		return err
+	}
+	groomer := status_groomer.Groomer{
+		GroupingStrategy: parser.Parse(groups),
+		Host:             os.Getenv("CONCOURSE_HOST"),
+		Team:             os.Getenv("CONCOURSE_TEAM"),
+		Concourse:        concourse.ConcourseClient{},
+		AutoGroup:        autoGroup,
+	}
+	if err := groomer.LoadAutoGroups(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s", pipeline, job)
+	matches := parser.Matches(groups, name)
+	if group := groomer.AutoGroupOf(pipeline, job); group != "" {
+		fmt.Printf("%s is in Concourse group %s\n", name, group)
+		matches = append(matches, parser.Matches(groups, group)...)
+	}
+	matched := map[string]bool{}
+	for _, m := range matches {
+		matched[m.Group] = true
+	}
+	// rules are tried by group name, so with several groups the first wins and
+	// shadows the others
+	winner := ""
+	switch len(matched) {
+	case 0:
+		if group := groomer.AutoGroupOf(pipeline, job); group != "" {
+			fmt.Printf("%s matches no group, so it is grouped by its Concourse group\n", name)
+		} else {
+			fmt.Printf("%s matches no group\n", name)
+		}
+	case 1:
+		fmt.Printf("%s matches group %s\n", name, matches[0].Group)
+	default:
+		_, winner = groomer.GroupRule(pipeline, job)
+		fmt.Printf("%s matches more than one group and is filed under %s:\n", name, winner)
+	}
+	for _, m := range matches {
+		if winner != "" && m.Group != winner {
+			fmt.Printf("  group %s with pattern %q (shadowed)\n", m.Group, m.Pattern)
+			continue
+		}
+		fmt.Printf("  group %s with pattern %q\n", m.Group, m.Pattern)
+	}
+
+	fmt.Printf("story: %q\n", groomer.Title(pipeline, job))
+	return nil
+}
@@ -0,0 +1,228 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type Client struct {
+	GitHubAPI  string
+	Token      string
+	Owner      string
+	Repo       string
+	Labels     []string
+	HTTPClient *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+type issue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	HTMLURL     string          `json:"html_url"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+type issueRequest struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels"`
+}
+
+type comment struct {
+	Body string `json:"body"`
+}
+
+func (c Client) labels() []string {
+	if len(c.Labels) > 0 {
+		return c.Labels
+	}
+	return []string{"broken build"}
+}
+
+func (c Client) repoURL(format string, args ...interface{}) string {
+	return fmt.Sprintf("%s/repos/%s/%s", c.GitHubAPI, c.Owner, c.Repo) + fmt.Sprintf(format, args...)
+}
+
+func (c Client) do(method, rawURL string, input interface{}, output interface{}) (string, error) {
+	var body bytes.Buffer
+	if input != nil {
+		if err := json.NewEncoder(&body).Encode(input); err != nil {
+			return "", err
+		}
+	}
+
+	req, err := http.NewRequest(method, rawURL, &body)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "token "+c.Token)
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", newError(resp)
+	}
+
+	if output != nil {
+		if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+			return "", err
+		}
+	}
+	return nextPage(resp.Header.Get("Link")), nil
+}
+
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func nextPage(link string) string {
+	match := nextLink.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func (c Client) FindOpenIssue(title string) (*status_groomer.Issue, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("labels", c.labels()[0])
+	query.Set("per_page", "100")
+
+	next := c.repoURL("/issues?%s", query.Encode())
+	for next != "" {
+		var page []issue
+		var err error
+		next, err = c.do("GET", next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range page {
+			if i.PullRequest == nil && i.Title == title {
+				found := toIssue(i)
+				return &found, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (c Client) CreateIssue(input status_groomer.NewIssue) (status_groomer.Issue, error) {
+	var created issue
+	_, err := c.do("POST", c.repoURL("/issues"), issueRequest{
+		Title:  input.Title,
+		Body:   render(input.Comment),
+		Labels: c.labels(),
+	}, &created)
+	if err != nil {
+		return status_groomer.Issue{}, err
+	}
+	return toIssue(created), nil
+}
+
+// FileReport opens an issue without the broken build labels.
+func (c Client) FileReport(title string, comment status_groomer.Comment) (status_groomer.Issue, error) {
+	var created issue
+	_, err := c.do("POST", c.repoURL("/issues"), issueRequest{
+		Title:  title,
+		Body:   render(comment),
+		Labels: []string{},
+	}, &created)
+	if err != nil {
+		return status_groomer.Issue{}, err
+	}
+	return toIssue(created), nil
+}
+
+func (c Client) Comments(issueID string) ([]string, error) {
+	texts := []string{}
+	next := c.repoURL("/issues/%s/comments?per_page=100", issueID)
+	for next != "" {
+		var page []comment
+		var err error
+		next, err = c.do("GET", next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page {
+			texts = append(texts, c.Body)
+		}
+	}
+
+	// the body of the issue holds the build that opened it
+	var opened issue
+	if _, err := c.do("GET", c.repoURL("/issues/%s", issueID), nil, &opened); err != nil {
+		return nil, err
+	}
+	return append([]string{opened.Body}, texts...), nil
+}
+
+func (c Client) AddComment(issueID string, input status_groomer.Comment) error {
+	_, err := c.do("POST", c.repoURL("/issues/%s/comments", issueID), comment{Body: render(input)}, nil)
+	return err
+}
+
+func (c Client) AddLabel(issueID string, label string) error {
+	_, err := c.do("POST", c.repoURL("/issues/%s/labels", issueID), map[string][]string{
+		"labels": {label},
+	}, nil)
+	return err
+}
+
+func (c Client) ResolveIssue(issueID string) error {
+	_, err := c.do("PATCH", c.repoURL("/issues/%s", issueID), map[string]string{
+		"state":        "closed",
+		"state_reason": "completed",
+	}, nil)
+	return err
+}
+
+func (c Client) ReopenIssue(issueID string) error {
+	_, err := c.do("PATCH", c.repoURL("/issues/%s", issueID), map[string]string{
+		"state": "open",
+	}, nil)
+	return err
+}
+
+func toIssue(i issue) status_groomer.Issue {
+	return status_groomer.Issue{
+		ID:    strconv.Itoa(i.Number),
+		Title: i.Title,
+		URL:   i.HTMLURL,
+	}
+}
+
+func render(c status_groomer.Comment) string {
+	switch {
+	case c.Text == "":
+		return c.Link
+	case c.Link == "":
+		return c.Text
+	default:
+		return fmt.Sprintf("%s\n\n%s", c.Text, c.Link)
+	}
+}
@@ -0,0 +1,307 @@
+package github_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+
+	"github.com/jaresty/concourse-tracker-bot/github"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	HTMLURL     string          `json:"html_url"`
+	Labels      []string        `json:"-"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// fakeGitHub is a minimal in-memory stand-in for the parts of the GitHub
+// issues API that the adapter uses. It pages issue lists two at a time.
+type fakeGitHub struct {
+	issues   []*fakeIssue
+	comments map[int][]string
+	server   *httptest.Server
+}
+
+var (
+	issuesPath   = regexp.MustCompile(`^/repos/owner/repo/issues$`)
+	issuePath    = regexp.MustCompile(`^/repos/owner/repo/issues/(\d+)$`)
+	commentsPath = regexp.MustCompile(`^/repos/owner/repo/issues/(\d+)/comments$`)
+	labelsPath   = regexp.MustCompile(`^/repos/owner/repo/issues/(\d+)/labels$`)
+)
+
+func newFakeGitHub() *fakeGitHub {
+	f := &fakeGitHub{comments: map[int][]string{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakeGitHub) find(number string) *fakeIssue {
+	n, _ := strconv.Atoi(number)
+	for _, i := range f.issues {
+		if i.Number == n {
+			return i
+		}
+	}
+	return nil
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token my-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+		return
+	}
+
+	switch {
+	case issuesPath.MatchString(r.URL.Path) && r.Method == "GET":
+		matching := []*fakeIssue{}
+		for _, i := range f.issues {
+			if i.State != r.URL.Query().Get("state") {
+				continue
+			}
+			for _, l := range i.Labels {
+				if l == r.URL.Query().Get("labels") {
+					matching = append(matching, i)
+				}
+			}
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		start, end := (page-1)*2, page*2
+		if end >= len(matching) {
+			end = len(matching)
+		} else {
+			q := r.URL.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?%s>; rel="next"`, f.server.URL, r.URL.Path, q.Encode()))
+		}
+		json.NewEncoder(w).Encode(matching[start:end])
+
+	case issuesPath.MatchString(r.URL.Path) && r.Method == "POST":
+		var input struct {
+			Title  string   `json:"title"`
+			Body   string   `json:"body"`
+			Labels []string `json:"labels"`
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		created := &fakeIssue{
+			Number:  len(f.issues) + 1,
+			Title:   input.Title,
+			Body:    input.Body,
+			Labels:  input.Labels,
+			State:   "open",
+			HTMLURL: fmt.Sprintf("https://github.com/owner/repo/issues/%d", len(f.issues)+1),
+		}
+		f.issues = append(f.issues, created)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	case issuePath.MatchString(r.URL.Path):
+		i := f.find(issuePath.FindStringSubmatch(r.URL.Path)[1])
+		if i == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PATCH" {
+			var input map[string]string
+			json.NewDecoder(r.Body).Decode(&input)
+			i.State = input["state"]
+		}
+		json.NewEncoder(w).Encode(i)
+
+	case labelsPath.MatchString(r.URL.Path) && r.Method == "POST":
+		i := f.find(labelsPath.FindStringSubmatch(r.URL.Path)[1])
+		if i == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var input map[string][]string
+		json.NewDecoder(r.Body).Decode(&input)
+		i.Labels = append(i.Labels, input["labels"]...)
+		w.Write([]byte(`[]`))
+
+	case commentsPath.MatchString(r.URL.Path):
+		n, _ := strconv.Atoi(commentsPath.FindStringSubmatch(r.URL.Path)[1])
+		if r.Method == "POST" {
+			var input map[string]string
+			json.NewDecoder(r.Body).Decode(&input)
+			f.comments[n] = append(f.comments[n], input["body"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+			return
+		}
+		out := []map[string]string{}
+		for _, c := range f.comments[n] {
+			out = append(out, map[string]string{"body": c})
+		}
+		json.NewEncoder(w).Encode(out)
+
+	default:
+		w.WriteHeader(http.StatusTeapot)
+	}
+}
+
+var _ = Describe("Client", func() {
+	var (
+		fake   *fakeGitHub
+		client github.Client
+	)
+
+	BeforeEach(func() {
+		fake = newFakeGitHub()
+		client = github.Client{
+			GitHubAPI: fake.server.URL,
+			Token:     "my-token",
+			Owner:     "owner",
+			Repo:      "repo",
+		}
+	})
+
+	AfterEach(func() {
+		fake.server.Close()
+	})
+
+	It("satisfies the groomer's issue backend", func() {
+		var _ status_groomer.IssueBackend = client
+	})
+
+	Describe("FindOpenIssue", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{
+				{Number: 1, Title: "groupa has failed", State: "closed", Labels: []string{"broken build"}},
+				{Number: 2, Title: "groupb has failed", State: "open", Labels: []string{"broken build"}},
+				{Number: 3, Title: "groupa has failed", State: "open", Labels: []string{"broken build"}, PullRequest: json.RawMessage(`{}`)},
+				{Number: 4, Title: "groupc has failed", State: "open", Labels: []string{"broken build"}},
+				{Number: 5, Title: "groupa has failed", State: "open", Labels: []string{"broken build"}, HTMLURL: "https://github.com/owner/repo/issues/5"},
+				{Number: 6, Title: "groupd has failed", State: "open", Labels: []string{"something else"}},
+			}
+		})
+
+		It("finds an open issue with the title on a later page", func() {
+			issue, err := client.FindOpenIssue("groupa has failed")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(Equal(&status_groomer.Issue{
+				ID:    "5",
+				Title: "groupa has failed",
+				URL:   "https://github.com/owner/repo/issues/5",
+			}))
+		})
+
+		It("ignores issues without the broken build label", func() {
+			issue, err := client.FindOpenIssue("groupd has failed")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Describe("CreateIssue", func() {
+		It("opens a labelled issue with the build link in its body", func() {
+			issue, err := client.CreateIssue(status_groomer.NewIssue{
+				Title:   "groupa has failed",
+				Comment: status_groomer.Comment{Link: "https://ci/builds/1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.ID).To(Equal("1"))
+			Expect(fake.issues[0].Body).To(Equal("https://ci/builds/1"))
+			Expect(fake.issues[0].Labels).To(Equal([]string{"broken build"}))
+		})
+
+		It("uses the configured labels", func() {
+			client.Labels = []string{"ci", "red"}
+			_, err := client.CreateIssue(status_groomer.NewIssue{Title: "groupa has failed"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fake.issues[0].Labels).To(Equal([]string{"ci", "red"}))
+		})
+	})
+
+	Describe("FileReport", func() {
+		It("opens an issue without the broken build label", func() {
+			_, err := client.FileReport("Build health report", status_groomer.Comment{Text: "| Group |"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fake.issues[0].Body).To(Equal("| Group |"))
+			Expect(fake.issues[0].Labels).To(BeEmpty())
+		})
+	})
+
+	Describe("comments", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{
+				{Number: 7, Title: "groupa has failed", State: "open", Body: "https://ci/builds/1"},
+			}
+		})
+
+		It("adds comments and lists them after the issue body", func() {
+			err := client.AddComment("7", status_groomer.Comment{Text: "still failing", Link: "https://ci/builds/2"})
+			Expect(err).NotTo(HaveOccurred())
+
+			comments, err := client.Comments("7")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comments).To(Equal([]string{
+				"https://ci/builds/1",
+				"still failing\n\nhttps://ci/builds/2",
+			}))
+		})
+	})
+
+	Describe("ResolveIssue and ReopenIssue", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{{Number: 8, State: "open"}}
+		})
+
+		It("closes and reopens the issue", func() {
+			Expect(client.ResolveIssue("8")).To(Succeed())
+			Expect(fake.issues[0].State).To(Equal("closed"))
+
+			Expect(client.ReopenIssue("8")).To(Succeed())
+			Expect(fake.issues[0].State).To(Equal("open"))
+		})
+	})
+
+	Describe("AddLabel", func() {
+		It("adds the label to the issue", func() {
+			fake.issues = []*fakeIssue{{Number: 8, State: "open", Labels: []string{"broken build"}}}
+			Expect(client.AddLabel("8", "flaky")).To(Succeed())
+			Expect(fake.issues[0].Labels).To(Equal([]string{"broken build", "flaky"}))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a typed error when the token is rejected", func() {
+			client.Token = "wrong"
+
+			_, err := client.FindOpenIssue("groupa has failed")
+			Expect(err).To(MatchError("401 Unauthorized - Bad credentials"))
+
+			var githubErr *github.Error
+			Expect(errors.As(err, &githubErr)).To(BeTrue())
+			Expect(githubErr.Unauthorized()).To(BeTrue())
+		})
+
+		It("returns an error for a missing issue", func() {
+			err := client.ResolveIssue("404")
+			Expect(err).To(MatchError(ContainSubstring("404 Not Found")))
+		})
+
+		It("returns an error when url is malformed", func() {
+			client.GitHubAPI = "%%"
+
+			_, err := client.CreateIssue(status_groomer.NewIssue{})
+			Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type Error struct {
+	StatusCode int    `json:"-"`
+	Status     string `json:"-"`
+	Body       string `json:"-"`
+	Message    string `json:"message"`
+}
+
+func newError(resp *http.Response) *Error {
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+	json.Unmarshal(body, e)
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s - %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s - %s", e.Status, e.Body)
+}
+
+func (e *Error) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// Forbidden excludes GitHub's secondary rate limit, which is also a 403.
+func (e *Error) Forbidden() bool {
+	return e.StatusCode == http.StatusForbidden && !e.Temporary()
+}
+
+func (e *Error) Temporary() bool {
+	if e.StatusCode == http.StatusForbidden {
+		return strings.Contains(e.Message, "rate limit")
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
@@ -0,0 +1,13 @@
+package groupconfig_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGroupconfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Group Config Suite")
+}
@@ -0,0 +1,133 @@
+// Package groupconfig keeps the grouping rules of a running bot in step with
+// its group config file.
+package groupconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// Watcher reloads the group config every interval, after every poll cycle or
+// when signalled, e.g. on SIGHUP. Each new valid version is handed to Apply;
+// when the source fails or the new version is invalid the reason is logged
+// and the last good config stays in use.
+type Watcher struct {
+	Source parser.Source
+	// Cache, when set, is a file keeping the last good config, which the bot
+	// starts from when the source is unreachable or invalid.
+	Cache    string
+	Interval time.Duration
+	Apply    func(map[string]string)
+	Log      logging.Logger
+
+	mu   sync.Mutex
+	last []byte
+}
+
+// Load fetches the config the bot starts with.
+func (w *Watcher) Load() (map[string][]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, groups, err := w.fetch()
+	if err != nil && w.Cache != "" {
+		cached, cacheErr := ioutil.ReadFile(w.Cache)
+		if cacheErr == nil {
+			if groups, cacheErr = parse(cached); cacheErr == nil {
+				w.Log.Warn("starting from the cached group config", "source", w.Source.String(), "cache", w.Cache, "error", err)
+				data, err = cached, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.keep(data)
+	return groups, nil
+}
+
+// Run reloads the config every interval, unless it is zero, and on every
+// signal until stop is closed.
+func (w *Watcher) Run(stop <-chan struct{}, signals <-chan os.Signal) {
+	var tick <-chan time.Time
+	if w.Interval > 0 {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-signals:
+			w.Reload()
+		case <-tick:
+			w.Reload()
+		}
+	}
+}
+
+// CycleFinished reloads the config in the background so that the groomer
+// picks it up before its next cycle.
+func (w *Watcher) CycleFinished(status_groomer.CycleStats) {
+	go w.Reload()
+}
+
+// Reload fetches and validates the config and applies it if it changed.
+// Reloads run one at a time so that an older fetch is never applied over a
+// newer one.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, groups, err := w.fetch()
+	if err != nil {
+		w.Log.Warn("keeping the previous group config", "source", w.Source.String(), "error", err)
+		return err
+	}
+	if w.last != nil && bytes.Equal(data, w.last) {
+		return nil
+	}
+	w.keep(data)
+	w.Apply(parser.Parse(groups))
+	w.Log.Info("reloaded the group config", "source", w.Source.String(), "groups", len(groups))
+	return nil
+}
+
+func (w *Watcher) fetch() ([]byte, map[string][]string, error) {
+	data, err := w.Source.Fetch()
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := parse(data)
+	return data, groups, err
+}
+
+func (w *Watcher) keep(data []byte) {
+	w.last = data
+	if w.Cache == "" {
+		return
+	}
+	if err := ioutil.WriteFile(w.Cache, data, 0644); err != nil {
+		w.Log.Warn("failed to cache the group config", "cache", w.Cache, "error", err)
+	}
+}
+
+func parse(data []byte) (map[string][]string, error) {
+	groups, err := parser.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.Validate(groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
@@ -0,0 +1,215 @@
+package groupconfig_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	. "github.com/jaresty/concourse-tracker-bot/groupconfig"
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type unreachable struct{}
+
+func (unreachable) Fetch() ([]byte, error) { return nil, errors.New("connection refused") }
+func (unreachable) String() string         { return "https://example.com/groups.yml" }
+
+// staleSource holds its first fetch until released and then returns an older
+// config than every later fetch.
+type staleSource struct {
+	fetching chan struct{}
+	release  chan struct{}
+	fetches  int32
+}
+
+func (s *staleSource) Fetch() ([]byte, error) {
+	if atomic.AddInt32(&s.fetches, 1) == 1 {
+		close(s.fetching)
+		<-s.release
+		return []byte("luna:\n- cf-deployment-.*-fresh\n"), nil
+	}
+	return []byte("sol:\n- cf-acceptance\n"), nil
+}
+
+func (s *staleSource) String() string { return "https://example.com/groups.yml" }
+
+var _ = Describe("Watcher", func() {
+	var (
+		dir      string
+		path     string
+		applied  chan map[string]string
+		log      logging.Recorder
+		watcher  *Watcher
+		stop     chan struct{}
+		signals  chan os.Signal
+		write    func(string)
+		keepsOld = "keeping the previous group config"
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "groups")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "groups.yml")
+		write = func(content string) {
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		}
+		write("luna:\n- cf-deployment-.*-fresh\n")
+
+		applied = make(chan map[string]string, 10)
+		log = logging.NewRecorder()
+		watcher = &Watcher{
+			Source:   parser.File(path),
+			Interval: 10 * time.Millisecond,
+			Apply:    func(strategy map[string]string) { applied <- strategy },
+			Log:      log,
+		}
+		stop = make(chan struct{})
+		signals = make(chan os.Signal, 1)
+	})
+
+	AfterEach(func() {
+		close(stop)
+		os.RemoveAll(dir)
+	})
+
+	Describe("Load", func() {
+		It("returns the grouping rules without applying them", func() {
+			Expect(watcher.Load()).To(Equal(map[string][]string{"luna": {"cf-deployment-.*-fresh"}}))
+			Expect(applied).NotTo(Receive())
+		})
+
+		It("fails on an invalid config", func() {
+			write("luna:\n- cf-deployment-(\n")
+
+			_, err := watcher.Load()
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("with a cache", func() {
+			BeforeEach(func() {
+				watcher.Cache = filepath.Join(dir, "groups.cache.yml")
+			})
+
+			It("keeps a copy of the config", func() {
+				_, err := watcher.Load()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(watcher.Cache)).To(Equal([]byte("luna:\n- cf-deployment-.*-fresh\n")))
+			})
+
+			It("starts from the copy when the source is unreachable", func() {
+				Expect(ioutil.WriteFile(watcher.Cache, []byte("sol:\n- cf-acceptance\n"), 0644)).To(Succeed())
+				watcher.Source = unreachable{}
+
+				Expect(watcher.Load()).To(Equal(map[string][]string{"sol": {"cf-acceptance"}}))
+				entry, ok := log.Find("starting from the cached group config")
+				Expect(ok).To(BeTrue())
+				Expect(entry.Fields).To(HaveKeyWithValue("source", "https://example.com/groups.yml"))
+			})
+
+			It("fails when there is no copy either", func() {
+				watcher.Source = unreachable{}
+
+				_, err := watcher.Load()
+				Expect(err).To(MatchError("connection refused"))
+			})
+		})
+	})
+
+	Describe("Reload", func() {
+		It("applies the grouping rules of a valid file", func() {
+			Expect(watcher.Reload()).To(Succeed())
+			Expect(applied).To(Receive(Equal(map[string]string{"(cf-deployment-.*-fresh)": "luna"})))
+		})
+
+		It("does not apply a config that did not change", func() {
+			_, err := watcher.Load()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(watcher.Reload()).To(Succeed())
+			Expect(applied).NotTo(Receive())
+		})
+
+		It("keeps the previous rules and logs why when the file is invalid", func() {
+			write("luna:\n- cf-deployment-(\n")
+
+			Expect(watcher.Reload()).NotTo(Succeed())
+			Expect(applied).NotTo(Receive())
+			entry, ok := log.Find(keepsOld)
+			Expect(ok).To(BeTrue())
+			Expect(entry.Level).To(Equal(logging.Warn))
+			Expect(entry.Fields).To(HaveKeyWithValue("source", path))
+			Expect(entry.Fields).To(HaveKey("error"))
+		})
+
+		It("never applies an older fetch over a newer one", func() {
+			source := &staleSource{fetching: make(chan struct{}), release: make(chan struct{})}
+			watcher.Source = source
+			go watcher.Reload()
+			Eventually(source.fetching).Should(BeClosed())
+
+			go watcher.Reload()
+			Consistently(applied, 50*time.Millisecond).ShouldNot(Receive())
+
+			close(source.release)
+			Eventually(applied).Should(Receive(HaveKey("(cf-deployment-.*-fresh)")))
+			Eventually(applied).Should(Receive(Equal(map[string]string{"(cf-acceptance)": "sol"})))
+		})
+
+		It("keeps the previous rules when the source is unreachable", func() {
+			watcher.Source = unreachable{}
+
+			Expect(watcher.Reload()).NotTo(Succeed())
+			Expect(applied).NotTo(Receive())
+			_, ok := log.Find(keepsOld)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("Run", func() {
+		BeforeEach(func() {
+			_, err := watcher.Load()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not reload the config it started with", func() {
+			go watcher.Run(stop, signals)
+
+			Consistently(applied, 50*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("reloads the file when it changes", func() {
+			go watcher.Run(stop, signals)
+
+			write("luna:\n- cf-deployment-.*-fresh\nsol:\n- cf-acceptance\n")
+			Eventually(applied).Should(Receive(HaveKeyWithValue("(cf-acceptance)", "sol")))
+		})
+
+		It("reloads the file on a signal", func() {
+			watcher.Interval = 0
+			go watcher.Run(stop, signals)
+
+			write("sol:\n- cf-acceptance\n")
+			signals <- syscall.SIGHUP
+			Eventually(applied).Should(Receive(Equal(map[string]string{"(cf-acceptance)": "sol"})))
+		})
+	})
+
+	It("reloads the config after every poll cycle", func() {
+		_, err := watcher.Load()
+		Expect(err).NotTo(HaveOccurred())
+
+		write("sol:\n- cf-acceptance\n")
+		watcher.CycleFinished(status_groomer.CycleStats{})
+		Eventually(applied).Should(Receive(Equal(map[string]string{"(cf-acceptance)": "sol"})))
+	})
+})
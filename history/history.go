@@ -0,0 +1,71 @@
+package history
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/jsonl"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// Log appends failures and recoveries to a file, one JSON object per line.
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (l *Log) Record(event status_groomer.BreakageEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return jsonl.Append(l.Path, event)
+}
+
+func (l *Log) Broken() (map[string]bool, error) {
+	events, err := l.Events()
+	if err != nil {
+		return nil, err
+	}
+
+	broken := map[string]bool{}
+	for _, event := range events {
+		broken[event.Title] = event.Type == status_groomer.BuildFailed
+	}
+	for title, red := range broken {
+		if !red {
+			delete(broken, title)
+		}
+	}
+	return broken, nil
+}
+
+// Events reads the whole history. A missing file is an empty history.
+func (l *Log) Events() ([]status_groomer.BreakageEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := []status_groomer.BreakageEvent{}
+	if err := jsonl.ReadFile(l.Path, collect(&events)); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func Read(r io.Reader) ([]status_groomer.BreakageEvent, error) {
+	events := []status_groomer.BreakageEvent{}
+	if err := jsonl.Read(r, collect(&events)); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func collect(events *[]status_groomer.BreakageEvent) func([]byte) error {
+	return func(line []byte) error {
+		var event status_groomer.BreakageEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return err
+		}
+		*events = append(*events, event)
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package history_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/history"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log", func() {
+	var (
+		dir string
+		log *history.Log
+		at  time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "history")
+		Expect(err).NotTo(HaveOccurred())
+		log = &history.Log{Path: filepath.Join(dir, "history.jsonl")}
+		at = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads a missing file as an empty history", func() {
+		events, err := log.Events()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("appends events and reads them back", func() {
+		failed := status_groomer.BreakageEvent{Type: status_groomer.BuildFailed, Time: at, Title: "luna has failed", Group: "luna", Pipeline: "cf", Job: "deploy", BuildID: 4}
+		recovered := status_groomer.BreakageEvent{Type: status_groomer.TitleRecovered, Time: at.Add(time.Hour), Title: "luna has failed", Group: "luna"}
+		Expect(log.Record(failed)).To(Succeed())
+		Expect(log.Record(recovered)).To(Succeed())
+
+		Expect(log.Events()).To(Equal([]status_groomer.BreakageEvent{failed, recovered}))
+	})
+
+	It("lists the titles that are still broken", func() {
+		Expect(log.Record(status_groomer.BreakageEvent{Type: status_groomer.BuildFailed, Time: at, Title: "luna has failed"})).To(Succeed())
+		Expect(log.Record(status_groomer.BreakageEvent{Type: status_groomer.BuildFailed, Time: at, Title: "snitch has failed"})).To(Succeed())
+		Expect(log.Record(status_groomer.BreakageEvent{Type: status_groomer.TitleRecovered, Time: at, Title: "luna has failed"})).To(Succeed())
+
+		Expect(log.Broken()).To(Equal(map[string]bool{"snitch has failed": true}))
+	})
+
+	It("reports the line of a corrupt event", func() {
+		Expect(ioutil.WriteFile(log.Path, []byte("{}\nnot json\n"), 0644)).To(Succeed())
+		_, err := log.Events()
+		Expect(err).To(MatchError(HavePrefix(log.Path + ": line 2 - ")))
+	})
+})
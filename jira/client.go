@@ -0,0 +1,386 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type Project struct {
+	Key       string   `yaml:"project"`
+	IssueType string   `yaml:"issue_type"`
+	Labels    []string `yaml:"labels"`
+}
+
+type Config struct {
+	URL               string `yaml:"url"`
+	APIVersion        int    `yaml:"api_version"`
+	Username          string `yaml:"username"`
+	Project           `yaml:",inline"`
+	Groups            map[string]Project `yaml:"groups"`
+	ResolveTransition string             `yaml:"resolve_transition"`
+	ReopenTransition  string             `yaml:"reopen_transition"`
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+type Client struct {
+	Config
+	Token      string
+	HTTPClient *http.Client
+}
+
+// project returns the Jira project a group files into, filling anything the
+// group leaves out from the top-level defaults.
+func (c Client) project(group string) Project {
+	p := c.Groups[group]
+	if p.Key == "" {
+		p.Key = c.Key
+	}
+	if p.IssueType == "" {
+		p.IssueType = c.IssueType
+	}
+	if p.IssueType == "" {
+		p.IssueType = "Bug"
+	}
+	if len(p.Labels) == 0 {
+		p.Labels = c.Labels
+	}
+	if len(p.Labels) == 0 {
+		p.Labels = []string{"broken-build"}
+	}
+	return p
+}
+
+func (c Client) projectKeys() []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, p := range append([]Project{c.project("")}, c.groupProjects()...) {
+		if p.Key != "" && !seen[p.Key] {
+			seen[p.Key] = true
+			keys = append(keys, p.Key)
+		}
+	}
+	return keys
+}
+
+func (c Client) groupProjects() []Project {
+	projects := []Project{}
+	for group := range c.Groups {
+		projects = append(projects, c.project(group))
+	}
+	return projects
+}
+
+func (c Client) version() int {
+	if c.APIVersion == 3 {
+		return 3
+	}
+	return 2
+}
+
+func (c Client) apiURL(format string, args ...interface{}) string {
+	return fmt.Sprintf("%s/rest/api/%d", strings.TrimRight(c.URL, "/"), c.version()) + fmt.Sprintf(format, args...)
+}
+
+func (c Client) do(method, rawURL string, input interface{}, output interface{}) error {
+	var body bytes.Buffer
+	if input != nil {
+		if err := json.NewEncoder(&body).Encode(input); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, rawURL, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp)
+	}
+
+	if output != nil {
+		return json.NewDecoder(resp.Body).Decode(output)
+	}
+	return nil
+}
+
+type searchResult struct {
+	StartAt    int `json:"startAt"`
+	MaxResults int `json:"maxResults"`
+	Total      int `json:"total"`
+	Issues     []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	} `json:"issues"`
+}
+
+func (c Client) FindOpenIssue(title string) (*status_groomer.Issue, error) {
+	quoted := []string{}
+	for _, key := range c.projectKeys() {
+		quoted = append(quoted, jqlString(key))
+	}
+	jql := fmt.Sprintf(`project in (%s) AND statusCategory != Done AND summary ~ %s`,
+		strings.Join(quoted, ", "), jqlString(jqlString(title)))
+
+	for startAt := 0; ; {
+		var result searchResult
+		err := c.do("POST", c.apiURL("/search"), map[string]interface{}{
+			"jql":        jql,
+			"startAt":    startAt,
+			"maxResults": 50,
+			"fields":     []string{"summary"},
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range result.Issues {
+			// summary ~ is a fuzzy text match, so confirm the exact title
+			if i.Fields.Summary == title {
+				issue := c.toIssue(i.Key, title)
+				return &issue, nil
+			}
+		}
+
+		startAt = result.StartAt + len(result.Issues)
+		if len(result.Issues) == 0 || startAt >= result.Total {
+			return nil, nil
+		}
+	}
+}
+
+func (c Client) CreateIssue(input status_groomer.NewIssue) (status_groomer.Issue, error) {
+	project := c.project(input.Group)
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	err := c.do("POST", c.apiURL("/issue"), map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": project.Key},
+			"issuetype":   map[string]string{"name": project.IssueType},
+			"summary":     input.Title,
+			"labels":      project.Labels,
+			"description": c.render(input.Comment),
+		},
+	}, &created)
+	if err != nil {
+		return status_groomer.Issue{}, err
+	}
+	return c.toIssue(created.Key, input.Title), nil
+}
+
+// FileReport files a task in the default project without the broken build
+// labels.
+func (c Client) FileReport(title string, comment status_groomer.Comment) (status_groomer.Issue, error) {
+	var created struct {
+		Key string `json:"key"`
+	}
+	err := c.do("POST", c.apiURL("/issue"), map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": c.project("").Key},
+			"issuetype":   map[string]string{"name": "Task"},
+			"summary":     title,
+			"labels":      []string{},
+			"description": c.render(comment),
+		},
+	}, &created)
+	if err != nil {
+		return status_groomer.Issue{}, err
+	}
+	return c.toIssue(created.Key, title), nil
+}
+
+func (c Client) Comments(issueID string) ([]string, error) {
+	texts := []string{}
+	for startAt := 0; ; {
+		var page struct {
+			StartAt  int `json:"startAt"`
+			Total    int `json:"total"`
+			Comments []struct {
+				Body json.RawMessage `json:"body"`
+			} `json:"comments"`
+		}
+		err := c.do("GET", c.apiURL("/issue/%s/comment?startAt=%d", issueID, startAt), nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, comment := range page.Comments {
+			texts = append(texts, bodyText(comment.Body))
+		}
+
+		startAt = page.StartAt + len(page.Comments)
+		if len(page.Comments) == 0 || startAt >= page.Total {
+			break
+		}
+	}
+
+	var issue struct {
+		Fields struct {
+			Description json.RawMessage `json:"description"`
+		} `json:"fields"`
+	}
+	if err := c.do("GET", c.apiURL("/issue/%s?fields=description", issueID), nil, &issue); err != nil {
+		return nil, err
+	}
+	return append([]string{bodyText(issue.Fields.Description)}, texts...), nil
+}
+
+func (c Client) AddComment(issueID string, comment status_groomer.Comment) error {
+	return c.do("POST", c.apiURL("/issue/%s/comment", issueID), map[string]interface{}{
+		"body": c.render(comment),
+	}, nil)
+}
+
+func (c Client) AddLabel(issueID string, label string) error {
+	return c.do("PUT", c.apiURL("/issue/%s", issueID), map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}, nil)
+}
+
+func (c Client) SetType(issueID string, issueType string) error {
+	return c.do("PUT", c.apiURL("/issue/%s", issueID), map[string]interface{}{
+		"fields": map[string]interface{}{
+			"issuetype": map[string]string{"name": issueType},
+		},
+	}, nil)
+}
+
+func (c Client) ResolveIssue(issueID string) error {
+	name := c.ResolveTransition
+	if name == "" {
+		name = "Done"
+	}
+	return c.transition(issueID, name)
+}
+
+func (c Client) ReopenIssue(issueID string) error {
+	name := c.ReopenTransition
+	if name == "" {
+		name = "Reopen"
+	}
+	return c.transition(issueID, name)
+}
+
+// transition moves the issue through the named workflow transition. Either
+// the transition's own name or the name of the status it leads to may be
+// configured, since workflows name them inconsistently.
+func (c Client) transition(issueID string, name string) error {
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.do("GET", c.apiURL("/issue/%s/transitions", issueID), nil, &available); err != nil {
+		return err
+	}
+
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+			return c.do("POST", c.apiURL("/issue/%s/transitions", issueID), map[string]interface{}{
+				"transition": map[string]string{"id": t.ID},
+			}, nil)
+		}
+	}
+	return fmt.Errorf("issue %s has no %q transition", issueID, name)
+}
+
+func (c Client) toIssue(key string, title string) status_groomer.Issue {
+	return status_groomer.Issue{
+		ID:    key,
+		Title: title,
+		URL:   fmt.Sprintf("%s/browse/%s", strings.TrimRight(c.URL, "/"), key),
+	}
+}
+
+// render formats a comment as wiki markup for API v2 and as an Atlassian
+// document for API v3, which no longer accepts markup.
+func (c Client) render(comment status_groomer.Comment) interface{} {
+	if c.version() == 3 {
+		return document(comment)
+	}
+
+	switch {
+	case comment.Text == "":
+		return fmt.Sprintf("[%s]", comment.Link)
+	case comment.Link == "":
+		return comment.Text
+	default:
+		return fmt.Sprintf("%s [%s]", comment.Text, comment.Link)
+	}
+}
+
+func document(comment status_groomer.Comment) map[string]interface{} {
+	content := []interface{}{}
+	if comment.Text != "" {
+		content = append(content, map[string]interface{}{"type": "text", "text": comment.Text + " "})
+	}
+	if comment.Link != "" {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": comment.Link,
+			"marks": []interface{}{
+				map[string]interface{}{"type": "link", "attrs": map[string]string{"href": comment.Link}},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"type":    "doc",
+		"version": 1,
+		"content": []interface{}{
+			map[string]interface{}{"type": "paragraph", "content": content},
+		},
+	}
+}
+
+// bodyText returns wiki markup bodies as-is and Atlassian documents as their
+// raw JSON, which still contains any link the groomer is looking for.
+func bodyText(body json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(body, &text); err == nil {
+		return text
+	}
+	return string(body)
+}
+
+func jqlString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
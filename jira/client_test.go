@@ -0,0 +1,416 @@
+package jira_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jaresty/concourse-tracker-bot/jira"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+type fakeIssue struct {
+	Key         string
+	Project     string
+	IssueType   string
+	Summary     string
+	Labels      []string
+	Description interface{}
+	Status      string
+	Comments    []interface{}
+}
+
+// fakeJira is a minimal in-memory stand-in for the Jira REST API. Search
+// and comment listings are paged one result at a time.
+type fakeJira struct {
+	issues   []*fakeIssue
+	searches []string
+	server   *httptest.Server
+}
+
+var (
+	issuePath       = regexp.MustCompile(`^/rest/api/\d/issue/([A-Z]+-\d+)$`)
+	commentPath     = regexp.MustCompile(`^/rest/api/\d/issue/([A-Z]+-\d+)/comment$`)
+	transitionsPath = regexp.MustCompile(`^/rest/api/\d/issue/([A-Z]+-\d+)/transitions$`)
+	jqlProjects     = regexp.MustCompile(`project in \(([^)]*)\)`)
+)
+
+func newFakeJira() *fakeJira {
+	f := &fakeJira{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakeJira) find(key string) *fakeIssue {
+	for _, i := range f.issues {
+		if i.Key == key {
+			return i
+		}
+	}
+	return nil
+}
+
+func (f *fakeJira) serve(w http.ResponseWriter, r *http.Request) {
+	if user, token, ok := r.BasicAuth(); !ok || user != "bot@example.com" || token != "my-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errorMessages": ["You are not authenticated."], "errors": {}}`))
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/rest/api/2/search" && r.Method == "POST":
+		var input struct {
+			JQL     string `json:"jql"`
+			StartAt int    `json:"startAt"`
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		f.searches = append(f.searches, input.JQL)
+
+		projects := jqlProjects.FindStringSubmatch(input.JQL)[1]
+		matching := []map[string]interface{}{}
+		for _, i := range f.issues {
+			if i.Status != "Done" && strings.Contains(projects, `"`+i.Project+`"`) {
+				matching = append(matching, map[string]interface{}{
+					"key":    i.Key,
+					"fields": map[string]string{"summary": i.Summary},
+				})
+			}
+		}
+
+		page := matching[:0]
+		if input.StartAt < len(matching) {
+			page = matching[input.StartAt : input.StartAt+1]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"startAt":    input.StartAt,
+			"maxResults": 1,
+			"total":      len(matching),
+			"issues":     page,
+		})
+
+	case r.URL.Path == "/rest/api/2/issue" && r.Method == "POST":
+		var input struct {
+			Fields struct {
+				Project     map[string]string `json:"project"`
+				IssueType   map[string]string `json:"issuetype"`
+				Summary     string            `json:"summary"`
+				Labels      []string          `json:"labels"`
+				Description interface{}       `json:"description"`
+			} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		key := fmt.Sprintf("%s-%d", input.Fields.Project["key"], len(f.issues)+1)
+		f.issues = append(f.issues, &fakeIssue{
+			Key:         key,
+			Project:     input.Fields.Project["key"],
+			IssueType:   input.Fields.IssueType["name"],
+			Summary:     input.Fields.Summary,
+			Labels:      input.Fields.Labels,
+			Description: input.Fields.Description,
+			Status:      "To Do",
+		})
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"key": key})
+
+	case issuePath.MatchString(r.URL.Path):
+		i := f.find(issuePath.FindStringSubmatch(r.URL.Path)[1])
+		if i == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessages": ["Issue does not exist or you do not have permission to see it."], "errors": {}}`))
+			return
+		}
+		if r.Method == "PUT" {
+			var input struct {
+				Fields struct {
+					IssueType map[string]string `json:"issuetype"`
+				} `json:"fields"`
+				Update struct {
+					Labels []map[string]string `json:"labels"`
+				} `json:"update"`
+			}
+			json.NewDecoder(r.Body).Decode(&input)
+			for _, op := range input.Update.Labels {
+				i.Labels = append(i.Labels, op["add"])
+			}
+			if name, ok := input.Fields.IssueType["name"]; ok {
+				i.IssueType = name
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"key":    i.Key,
+			"fields": map[string]interface{}{"description": i.Description},
+		})
+
+	case commentPath.MatchString(r.URL.Path):
+		i := f.find(commentPath.FindStringSubmatch(r.URL.Path)[1])
+		if i == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "POST" {
+			var input map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&input)
+			i.Comments = append(i.Comments, input["body"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		page := []map[string]interface{}{}
+		if startAt < len(i.Comments) {
+			page = append(page, map[string]interface{}{"body": i.Comments[startAt]})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"startAt":  startAt,
+			"total":    len(i.Comments),
+			"comments": page,
+		})
+
+	case transitionsPath.MatchString(r.URL.Path):
+		i := f.find(transitionsPath.FindStringSubmatch(r.URL.Path)[1])
+		transitions := map[string]string{"11": "To Do", "21": "In Progress", "31": "Done"}
+		if r.Method == "POST" {
+			var input struct {
+				Transition map[string]string `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&input)
+			i.Status = transitions[input.Transition["id"]]
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"transitions": []map[string]interface{}{
+				{"id": "11", "name": "Back to backlog", "to": map[string]string{"name": "To Do"}},
+				{"id": "21", "name": "Start work", "to": map[string]string{"name": "In Progress"}},
+				{"id": "31", "name": "Close", "to": map[string]string{"name": "Done"}},
+			},
+		})
+
+	default:
+		w.WriteHeader(http.StatusTeapot)
+	}
+}
+
+const config = `
+url: %s
+username: bot@example.com
+project: CI
+resolve_transition: Close
+reopen_transition: to do
+groups:
+  luna:
+    project: LUNA
+    issue_type: Task
+    labels: [luna, broken-build]
+`
+
+var _ = Describe("Client", func() {
+	var (
+		fake   *fakeJira
+		client jira.Client
+	)
+
+	BeforeEach(func() {
+		fake = newFakeJira()
+
+		var cfg jira.Config
+		Expect(yaml.Unmarshal([]byte(fmt.Sprintf(config, fake.server.URL)), &cfg)).To(Succeed())
+		client = jira.Client{Config: cfg, Token: "my-token"}
+	})
+
+	AfterEach(func() {
+		fake.server.Close()
+	})
+
+	It("satisfies the groomer's issue backend", func() {
+		var _ status_groomer.IssueBackend = client
+	})
+
+	Describe("CreateIssue", func() {
+		It("files the issue in the project, type and labels mapped to the group", func() {
+			issue, err := client.CreateIssue(status_groomer.NewIssue{
+				Title:   "luna has failed",
+				Group:   "luna",
+				Comment: status_groomer.Comment{Link: "https://ci/builds/1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(Equal(status_groomer.Issue{
+				ID:    "LUNA-1",
+				Title: "luna has failed",
+				URL:   fake.server.URL + "/browse/LUNA-1",
+			}))
+
+			Expect(fake.issues[0].IssueType).To(Equal("Task"))
+			Expect(fake.issues[0].Labels).To(Equal([]string{"luna", "broken-build"}))
+			Expect(fake.issues[0].Description).To(Equal("[https://ci/builds/1]"))
+		})
+
+		It("falls back to the default project for unmapped groups", func() {
+			_, err := client.CreateIssue(status_groomer.NewIssue{Title: "p/j has failed"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fake.issues[0].Project).To(Equal("CI"))
+			Expect(fake.issues[0].IssueType).To(Equal("Bug"))
+			Expect(fake.issues[0].Labels).To(Equal([]string{"broken-build"}))
+		})
+	})
+
+	Describe("FileReport", func() {
+		It("files a task in the default project without the broken build label", func() {
+			_, err := client.FileReport("Build health report", status_groomer.Comment{Text: "| Group |"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fake.issues[0].Project).To(Equal("CI"))
+			Expect(fake.issues[0].IssueType).To(Equal("Task"))
+			Expect(fake.issues[0].Labels).To(BeEmpty())
+		})
+	})
+
+	Describe("FindOpenIssue", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{
+				{Key: "CI-1", Project: "CI", Summary: "luna has failed again", Status: "To Do"},
+				{Key: "LUNA-2", Project: "LUNA", Summary: "luna has failed", Status: "Done"},
+				{Key: "OTHER-3", Project: "OTHER", Summary: "luna has failed", Status: "To Do"},
+				{Key: "LUNA-4", Project: "LUNA", Summary: "luna has failed", Status: "In Progress"},
+			}
+		})
+
+		It("searches every mapped project for an unresolved issue with the exact title", func() {
+			issue, err := client.FindOpenIssue(`luna has failed`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue.ID).To(Equal("LUNA-4"))
+
+			Expect(fake.searches[0]).To(Equal(`project in ("CI", "LUNA") AND statusCategory != Done AND summary ~ "\"luna has failed\""`))
+		})
+
+		It("returns nil when there is no open issue", func() {
+			issue, err := client.FindOpenIssue("snitch has failed")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issue).To(BeNil())
+		})
+	})
+
+	Describe("comments", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{
+				{Key: "CI-1", Project: "CI", Description: "[https://ci/builds/1]"},
+			}
+		})
+
+		It("writes comments in wiki markup and lists them after the description", func() {
+			Expect(client.AddComment("CI-1", status_groomer.Comment{Text: "still failing", Link: "https://ci/builds/2"})).To(Succeed())
+			Expect(client.AddComment("CI-1", status_groomer.Comment{Link: "https://ci/builds/3"})).To(Succeed())
+
+			comments, err := client.Comments("CI-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comments).To(Equal([]string{
+				"[https://ci/builds/1]",
+				"still failing [https://ci/builds/2]",
+				"[https://ci/builds/3]",
+			}))
+		})
+	})
+
+	Describe("transitions", func() {
+		BeforeEach(func() {
+			fake.issues = []*fakeIssue{{Key: "CI-1", Project: "CI", Status: "In Progress"}}
+		})
+
+		It("resolves and reopens through the configured workflow transitions", func() {
+			Expect(client.ResolveIssue("CI-1")).To(Succeed())
+			Expect(fake.issues[0].Status).To(Equal("Done"))
+
+			Expect(client.ReopenIssue("CI-1")).To(Succeed())
+			Expect(fake.issues[0].Status).To(Equal("To Do"))
+		})
+
+		It("returns an error when the workflow has no such transition", func() {
+			client.ResolveTransition = "Won't Fix"
+			Expect(client.ResolveIssue("CI-1")).To(MatchError(`issue CI-1 has no "Won't Fix" transition`))
+		})
+	})
+
+	Describe("AddLabel", func() {
+		It("adds the label without touching the others", func() {
+			fake.issues = []*fakeIssue{{Key: "CI-1", Project: "CI", Labels: []string{"broken-build"}}}
+			Expect(client.AddLabel("CI-1", "flaky")).To(Succeed())
+			Expect(fake.issues[0].Labels).To(Equal([]string{"broken-build", "flaky"}))
+		})
+	})
+
+	Describe("SetType", func() {
+		It("changes the issue type", func() {
+			fake.issues = []*fakeIssue{{Key: "CI-1", Project: "CI", IssueType: "Task"}}
+			Expect(client.SetType("CI-1", "Bug")).To(Succeed())
+			Expect(fake.issues[0].IssueType).To(Equal("Bug"))
+		})
+	})
+
+	Context("with API version 3", func() {
+		BeforeEach(func() {
+			client.APIVersion = 3
+			fake.issues = []*fakeIssue{{Key: "CI-1", Project: "CI"}}
+		})
+
+		It("sends comments as Atlassian documents", func() {
+			err := client.AddComment("CI-1", status_groomer.Comment{Text: "still failing", Link: "https://ci/builds/2"})
+			Expect(err).NotTo(HaveOccurred())
+
+			body, _ := json.Marshal(fake.issues[0].Comments[0])
+			Expect(body).To(MatchJSON(`{
+				"type": "doc",
+				"version": 1,
+				"content": [{
+					"type": "paragraph",
+					"content": [
+						{"type": "text", "text": "still failing "},
+						{"type": "text", "text": "https://ci/builds/2", "marks": [{"type": "link", "attrs": {"href": "https://ci/builds/2"}}]}
+					]
+				}]
+			}`))
+
+			comments, err := client.Comments("CI-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(comments[1]).To(ContainSubstring("https://ci/builds/2"))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns a typed error when the credentials are rejected", func() {
+			client.Token = "wrong"
+
+			_, err := client.FindOpenIssue("luna has failed")
+			Expect(err).To(MatchError("401 Unauthorized - You are not authenticated."))
+
+			var jiraErr *jira.Error
+			Expect(errors.As(err, &jiraErr)).To(BeTrue())
+			Expect(jiraErr.Unauthorized()).To(BeTrue())
+		})
+
+		It("returns an error for a missing issue", func() {
+			_, err := client.Comments("CI-404")
+			Expect(err).To(MatchError("404 Not Found - "))
+		})
+
+		It("returns an error when url is malformed", func() {
+			client.URL = "%%"
+
+			_, err := client.CreateIssue(status_groomer.NewIssue{})
+			Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type Error struct {
+	StatusCode    int               `json:"-"`
+	Status        string            `json:"-"`
+	Body          string            `json:"-"`
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+func newError(resp *http.Response) *Error {
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+	json.Unmarshal(body, e)
+	return e
+}
+
+func (e *Error) Error() string {
+	messages := append([]string{}, e.ErrorMessages...)
+	fields := []string{}
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, e.Errors[field]))
+	}
+
+	if len(messages) == 0 {
+		return fmt.Sprintf("%s - %s", e.Status, e.Body)
+	}
+	return fmt.Sprintf("%s - %s", e.Status, strings.Join(messages, "; "))
+}
+
+func (e *Error) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *Error) Forbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
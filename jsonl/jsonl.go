@@ -0,0 +1,64 @@
+// Package jsonl appends to and reads files of one JSON object per line, as
+// kept by the audit log and the build history.
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Append writes value to the end of the file at path as a single line,
+// creating the file if needed.
+func Append(path string, value interface{}) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read calls each with every non-blank line of r, stopping at the first error
+// along with the number of the line it is about.
+func Read(r io.Reader, each func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := each(scanner.Bytes()); err != nil {
+			return fmt.Errorf("line %d - %s", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// ReadFile reads the file at path like Read. A missing file has no lines.
+func ReadFile(path string, each func(line []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := Read(f, each); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	return nil
+}
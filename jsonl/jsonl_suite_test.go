@@ -0,0 +1,13 @@
+package jsonl_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJsonl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "JSONL Suite")
+}
@@ -0,0 +1,87 @@
+package jsonl_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jaresty/concourse-tracker-bot/jsonl"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type line struct {
+	N int `json:"n"`
+}
+
+var _ = Describe("JSONL files", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "jsonl")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "lines.jsonl")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	readAll := func() ([]line, error) {
+		lines := []line{}
+		err := jsonl.ReadFile(path, func(data []byte) error {
+			var l line
+			if err := json.Unmarshal(data, &l); err != nil {
+				return err
+			}
+			lines = append(lines, l)
+			return nil
+		})
+		return lines, err
+	}
+
+	It("appends a line for each value and reads them back in order", func() {
+		Expect(jsonl.Append(path, line{N: 1})).To(Succeed())
+		Expect(jsonl.Append(path, line{N: 2})).To(Succeed())
+
+		data, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("{\"n\":1}\n{\"n\":2}\n"))
+		Expect(readAll()).To(Equal([]line{{N: 1}, {N: 2}}))
+	})
+
+	It("reads a missing file as no lines", func() {
+		Expect(readAll()).To(BeEmpty())
+	})
+
+	It("skips blank lines and reports the line that failed", func() {
+		err := jsonl.Read(strings.NewReader("{}\n\n{}\n"), func(data []byte) error {
+			if string(data) == "{}" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+		Expect(err).To(MatchError("line 1 - boom"))
+
+		n := 0
+		Expect(jsonl.Read(strings.NewReader("{}\n  \n{}\n"), func([]byte) error {
+			n++
+			return nil
+		})).To(Succeed())
+		Expect(n).To(Equal(2))
+	})
+
+	It("names the file when a line cannot be read", func() {
+		Expect(ioutil.WriteFile(path, []byte("not json\n"), 0644)).To(Succeed())
+		_, err := readAll()
+		Expect(err).To(MatchError(HavePrefix(path + ": line 1 - ")))
+	})
+})
@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < Debug || l > Error {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+// Logger writes messages with key/value fields, e.g.
+//
+//	log.Info("creating a new story", "pipeline", "cf-deployment", "job", "deploy")
+//
+// With returns a logger that adds its fields to every message.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+	With(keyvals ...interface{}) Logger
+}
+
+type Format string
+
+const (
+	Logfmt Format = "logfmt"
+	JSON   Format = "json"
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case Logfmt, JSON:
+		return Format(name), nil
+	}
+	return "", fmt.Errorf("unknown log format %q, expected logfmt or json", name)
+}
+
+type output struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Format
+	level  Level
+}
+
+type logger struct {
+	out    *output
+	fields []interface{}
+}
+
+// New returns a logger that writes messages at level or above to w, one line
+// per message.
+func New(w io.Writer, format Format, level Level) Logger {
+	return logger{out: &output{w: w, format: format, level: level}}
+}
+
+func (l logger) Debug(msg string, keyvals ...interface{}) { l.log(Debug, msg, keyvals) }
+func (l logger) Info(msg string, keyvals ...interface{})  { l.log(Info, msg, keyvals) }
+func (l logger) Warn(msg string, keyvals ...interface{})  { l.log(Warn, msg, keyvals) }
+func (l logger) Error(msg string, keyvals ...interface{}) { l.log(Error, msg, keyvals) }
+
+func (l logger) With(keyvals ...interface{}) Logger {
+	return logger{out: l.out, fields: append(append([]interface{}{}, l.fields...), keyvals...)}
+}
+
+func (l logger) log(level Level, msg string, keyvals []interface{}) {
+	if level < l.out.level {
+		return
+	}
+
+	fields := []interface{}{"time", time.Now().UTC().Format(time.RFC3339), "level", level.String(), "msg", msg}
+	fields = append(append(fields, l.fields...), keyvals...)
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(missing)")
+	}
+
+	var line bytes.Buffer
+	if l.out.format == JSON {
+		writeJSON(&line, fields)
+	} else {
+		writeLogfmt(&line, fields)
+	}
+	line.WriteByte('\n')
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(line.Bytes())
+}
+
+func writeJSON(buf *bytes.Buffer, fields []interface{}) {
+	buf.WriteByte('{')
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		value, err := json.Marshal(jsonValue(fields[i+1]))
+		if err != nil {
+			value, _ = json.Marshal(fmt.Sprint(fields[i+1]))
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+func writeLogfmt(buf *bytes.Buffer, fields []interface{}) {
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(fmt.Sprint(fields[i]))
+		buf.WriteByte('=')
+		value := fmt.Sprint(fields[i+1])
+		if value == "" || strings.ContainsAny(value, " =\"\\\n\t") {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+	}
+}
+
+// Discard drops every message.
+var Discard Logger = discard{}
+
+type discard struct{}
+
+func (discard) Debug(string, ...interface{}) {}
+func (discard) Info(string, ...interface{})  {}
+func (discard) Warn(string, ...interface{})  {}
+func (discard) Error(string, ...interface{}) {}
+func (d discard) With(...interface{}) Logger { return d }
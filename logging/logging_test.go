@@ -0,0 +1,89 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger", func() {
+	var out bytes.Buffer
+
+	BeforeEach(func() {
+		out.Reset()
+	})
+
+	It("writes logfmt lines with the logger's fields first", func() {
+		log := logging.New(&out, logging.Logfmt, logging.Info).With("cycle_id", 3)
+		log.Info("creating a new story", "pipeline", "cf-deployment", "title", "luna has failed")
+
+		Expect(out.String()).To(MatchRegexp(`^time=\S+ level=info msg="creating a new story" cycle_id=3 pipeline=cf-deployment title="luna has failed"\n$`))
+	})
+
+	It("writes JSON lines", func() {
+		log := logging.New(&out, logging.JSON, logging.Info)
+		log.Warn("poll failed", "error", errors.New("concourse is down"), "build_id", 42, "interval", 5*time.Minute)
+
+		var line map[string]interface{}
+		Expect(json.Unmarshal(out.Bytes(), &line)).To(Succeed())
+		Expect(line).To(HaveKeyWithValue("level", "warn"))
+		Expect(line).To(HaveKeyWithValue("msg", "poll failed"))
+		Expect(line).To(HaveKeyWithValue("error", "concourse is down"))
+		Expect(line).To(HaveKeyWithValue("build_id", 42.0))
+		Expect(line).To(HaveKeyWithValue("interval", "5m0s"))
+		Expect(line).To(HaveKey("time"))
+	})
+
+	It("drops messages below its level", func() {
+		log := logging.New(&out, logging.Logfmt, logging.Warn)
+		log.Debug("retrieving jobs")
+		log.Info("creating a new story")
+		log.Error("the issue tracker rejected the bot's credentials")
+
+		Expect(out.String()).NotTo(ContainSubstring("retrieving jobs"))
+		Expect(out.String()).NotTo(ContainSubstring("creating a new story"))
+		Expect(out.String()).To(ContainSubstring("level=error"))
+	})
+
+	It("does not share fields between loggers derived from the same parent", func() {
+		log := logging.New(&out, logging.Logfmt, logging.Info)
+		log.With("job", "a").Info("one")
+		log.With("job", "b").Info("two")
+
+		Expect(out.String()).To(ContainSubstring(`msg=one job=a`))
+		Expect(out.String()).To(ContainSubstring(`msg=two job=b`))
+	})
+
+	It("parses levels and formats", func() {
+		level, err := logging.ParseLevel("DEBUG")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(level).To(Equal(logging.Debug))
+		_, err = logging.ParseLevel("verbose")
+		Expect(err).To(MatchError(`unknown log level "verbose", expected debug, info, warn or error`))
+
+		format, err := logging.ParseFormat("json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(format).To(Equal(logging.JSON))
+		_, err = logging.ParseFormat("xml")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Recorder", func() {
+	It("records messages with their fields", func() {
+		recorder := logging.NewRecorder()
+		recorder.With("cycle_id", 1).Info("created story", "story_id", "7")
+
+		entry, ok := recorder.Find("created story")
+		Expect(ok).To(BeTrue())
+		Expect(entry.Level).To(Equal(logging.Info))
+		Expect(entry.Fields).To(Equal(map[string]interface{}{"cycle_id": 1, "story_id": "7"}))
+		Expect(recorder.Entries()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Entry struct {
+	Level   Level
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Recorder is a logger for tests that keeps every message so that specs can
+// assert on their levels and fields.
+type Recorder struct {
+	*recording
+	fields []interface{}
+}
+
+type recording struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewRecorder() Recorder {
+	return Recorder{recording: &recording{}}
+}
+
+func (r Recorder) Debug(msg string, keyvals ...interface{}) { r.record(Debug, msg, keyvals) }
+func (r Recorder) Info(msg string, keyvals ...interface{})  { r.record(Info, msg, keyvals) }
+func (r Recorder) Warn(msg string, keyvals ...interface{})  { r.record(Warn, msg, keyvals) }
+func (r Recorder) Error(msg string, keyvals ...interface{}) { r.record(Error, msg, keyvals) }
+
+func (r Recorder) With(keyvals ...interface{}) Logger {
+	return Recorder{recording: r.recording, fields: append(append([]interface{}{}, r.fields...), keyvals...)}
+}
+
+func (r Recorder) record(level Level, msg string, keyvals []interface{}) {
+	fields := map[string]interface{}{}
+	all := append(append([]interface{}{}, r.fields...), keyvals...)
+	for i := 0; i+1 < len(all); i += 2 {
+		fields[fmt.Sprint(all[i])] = all[i+1]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, Entry{Level: level, Message: msg, Fields: fields})
+}
+
+func (r Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry{}, r.entries...)
+}
+
+// Find returns the first entry with the message msg.
+func (r Recorder) Find(msg string) (Entry, bool) {
+	for _, entry := range r.Entries() {
+		if entry.Message == msg {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
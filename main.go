@@ -1,49 +1,350 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"io/ioutil"
-	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jaresty/concourse-tracker-bot/audit"
 	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/config"
+	"github.com/jaresty/concourse-tracker-bot/github"
+	"github.com/jaresty/concourse-tracker-bot/groupconfig"
+	"github.com/jaresty/concourse-tracker-bot/history"
+	"github.com/jaresty/concourse-tracker-bot/jira"
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/notifier"
 	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/state"
 	"github.com/jaresty/concourse-tracker-bot/status_groomer"
 	"github.com/jaresty/concourse-tracker-bot/tracker"
 	"gopkg.in/yaml.v2"
 )
 
-func parse(groupConfigFile string) map[string]string {
-	data, err := ioutil.ReadFile(groupConfigFile)
+const usage = `usage: concourse-tracker-bot <command> [flags]
+
+commands:
+  run                     poll Concourse and groom stories until stopped (default)
+  once                    run a single pass, e.g. from a Concourse task or cron job
+  validate                check the group and bot config files without connecting anywhere
+  explain <pipeline> <job>
+                          show which group a job falls in and the story it is filed on
+  coverage                list the group every Concourse job falls in and rules that match nothing
+  audit                   show the changes the bot made in the issue tracker and why
+  report                  sum up breakages, time to recovery and failing jobs per group
+
+run "concourse-tracker-bot <command> -h" for the flags of a command.
+`
+
+type command func(args []string) error
+
+var commands = map[string]command{
+	"run":      runCommand,
+	"once":     onceCommand,
+	"validate": validateCommand,
+	"explain":  explainCommand,
+	"coverage": coverageCommand,
+	"audit":    auditCommand,
+	"report":   reportCommand,
+}
+
+func main() {
+	name, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if name == "help" || !ok {
+		fmt.Fprint(os.Stderr, usage)
+		if name != "help" {
+			os.Exit(2)
+		}
+		return
+	}
+
+	if err := cmd(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// options are the flags shared by the commands that groom stories.
+type options struct {
+	groupConfigFile  string
+	groupConfigRef   string
+	groupConfigCache string
+	groupConfig      *groupconfig.Watcher
+	grouping         string
+	configFile       string
+	backendName      string
+	jiraConfigFile   string
+	resolveRecovered bool
+	dryRun           bool
+	planFormat       string
+	logLevel         string
+	logFormat        string
+	auditLog         string
+	historyFile      string
+	stateFile        string
+	httpConfig       tracker.HTTPConfig
+	// httpClient, when set, makes the requests to Concourse, GitHub, Jira
+	// and the notifiers; the Tracker client is built from httpConfig.
+	httpClient *http.Client
+}
+
+func (o *options) register(flags *flag.FlagSet) {
+	o.httpConfig = tracker.DefaultHTTPConfig()
+	flags.StringVar(&o.groupConfigFile, "group-config-file", "", "path or HTTP(S) URL of the group config file")
+	flags.StringVar(&o.groupConfigRef, "group-config-ref", "", "read the group config file at this git ref of the working copy it is in, e.g. origin/main")
+	flags.StringVar(&o.groupConfigCache, "group-config-cache", os.Getenv("GROUP_CONFIG_CACHE"), "file to keep the last good group config in, used at startup when the group config cannot be fetched")
+	flags.StringVar(&o.grouping, "grouping", envOr("GROUPING", "config"), "how to group jobs into stories: config, by the group config file only, or auto, by the Concourse group of each pipeline unless a rule of the group config file matches")
+	flags.StringVar(&o.configFile, "config-file", "", "path to the bot config file")
+	flags.StringVar(&o.backendName, "issue-backend", os.Getenv("ISSUE_BACKEND"), "issue tracker to file broken builds in: tracker, github or jira")
+	flags.StringVar(&o.jiraConfigFile, "jira-config-file", "", "path to the Jira project and workflow config file")
+	flags.BoolVar(&o.resolveRecovered, "resolve-recovered", false, "resolve a broken build story once every job in its group is green")
+	flags.BoolVar(&o.dryRun, "dry-run", false, "run a single pass and print the changes it would make instead of making them")
+	flags.StringVar(&o.planFormat, "plan-format", "text", "format of the dry run plan: text or json")
+	flags.StringVar(&o.logLevel, "log-level", envOr("LOG_LEVEL", "info"), "lowest level of messages to log: debug, info, warn or error")
+	flags.StringVar(&o.auditLog, "audit-log", os.Getenv("AUDIT_LOG"), "file to append a JSON line to for every change made in the issue tracker")
+	flags.StringVar(&o.historyFile, "history-file", os.Getenv("HISTORY_FILE"), "file to append a JSON line to for every failed build and recovery, read by the report command")
+	flags.StringVar(&o.stateFile, "state-file", os.Getenv("STATE_FILE"), "file to keep the escalations, flaky labels and ignored builds of stories in across restarts and runs of once")
+	flags.StringVar(&o.logFormat, "log-format", envOr("LOG_FORMAT", "logfmt"), "format of log lines: logfmt or json")
+	flags.DurationVar(&o.httpConfig.Timeout, "tracker-timeout", o.httpConfig.Timeout, "timeout for each Tracker API request")
+	flags.IntVar(&o.httpConfig.MaxRetries, "tracker-max-retries", o.httpConfig.MaxRetries, "number of times to retry a failed Tracker API request")
+	flags.Float64Var(&o.httpConfig.RequestsPerSecond, "tracker-rate-limit", o.httpConfig.RequestsPerSecond, "maximum Tracker API requests per second")
+}
+
+func (o *options) groupConfigSource() parser.Source {
+	return parser.NewSource(o.groupConfigFile, o.groupConfigRef)
+}
+
+func (o *options) autoGroup() (bool, error) {
+	switch o.grouping {
+	case "", "config":
+		return false, nil
+	case "auto":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown grouping %q, expected config or auto", o.grouping)
+}
+
+// groups loads the group config, keeping its watcher for run to reload it
+// with.
+func (o *options) groups(log logging.Logger) (map[string][]string, error) {
+	if o.groupConfigFile == "" {
+		return map[string][]string{}, nil
+	}
+	o.groupConfig = &groupconfig.Watcher{Source: o.groupConfigSource(), Cache: o.groupConfigCache, Log: log}
+	groups, err := o.groupConfig.Load()
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%s: %s", o.groupConfig.Source, err)
 	}
-	c := make(map[string][]string)
-	err = yaml.Unmarshal(data, c)
+	return groups, nil
+}
+
+func (o *options) groupingStrategy(log logging.Logger) (map[string]string, error) {
+	groups, err := o.groups(log)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return parser.Parse(c)
+	return parser.Parse(groups), nil
 }
 
-func main() {
-	var groupConfigFile string
-	flag.StringVar(&groupConfigFile, "group-config-file", "", "path to the group config file")
-	flag.Parse()
+// groomer builds a groomer from the flags, config files and environment.
+func (o *options) groomer(log logging.Logger) (*status_groomer.Groomer, *notifier.Webhooks, error) {
+	cfg, err := config.Load(o.configFile)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	host := os.Getenv("CONCOURSE_HOST")
-	team := os.Getenv("CONCOURSE_TEAM")
-	trackerToken := os.Getenv("TRACKER_API_TOKEN")
-	trackerProjectID, err := strconv.Atoi(os.Getenv("TRACKER_PROJECT_ID"))
+	strategy, err := o.groupingStrategy(log)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
-	client := tracker.Client{
-		APIToken:   trackerToken,
-		TrackerAPI: "https://www.pivotaltracker.com/services/v5",
+
+	autoGroup, err := o.autoGroup()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backend, err := issueBackend(o.backendName, o.httpConfig, o.jiraConfigFile, o.httpClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var auditor status_groomer.Auditor
+	if o.auditLog != "" {
+		auditor = &audit.Log{Path: o.auditLog}
+	}
+
+	var breakages status_groomer.BreakageHistory
+	if o.historyFile != "" {
+		breakages = &history.Log{Path: o.historyFile}
 	}
 
-	log := log.New(os.Stdout, "", log.Ldate|log.Ltime|log.Lshortfile)
-	status_groomer.Groom(parse(groupConfigFile), host, team, trackerProjectID, client, concourse.ConcourseClient{}, log, -1)
+	var store status_groomer.StateStore
+	if o.stateFile != "" {
+		store = &state.File{Path: o.stateFile}
+	}
+
+	// only notifiers that are configured are registered, since the groomer
+	// looks up the failing step of every failed build it announces
+	notifiers := []status_groomer.Notifier{}
+	if cfg.Notifications.Slack.Configured() {
+		cfg.Notifications.Slack.HTTPClient = o.httpClient
+		notifiers = append(notifiers, cfg.Notifications.Slack)
+	}
+	var webhooks *notifier.Webhooks
+	if len(cfg.Notifications.Webhooks.Endpoints) > 0 {
+		webhooks = notifier.NewWebhooks(cfg.Notifications.Webhooks, o.httpClient)
+		notifiers = append(notifiers, webhooks)
+	}
+
+	return &status_groomer.Groomer{
+		GroupingStrategy: strategy,
+		Host:             os.Getenv("CONCOURSE_HOST"),
+		Team:             os.Getenv("CONCOURSE_TEAM"),
+		Backend:          backend,
+		Concourse:        concourse.ConcourseClient{HTTPClient: o.httpClient},
+		Log:              log,
+		ResolveRecovered: o.resolveRecovered,
+		Notifiers:        notifiers,
+		History:          concourse.ConcourseClient{HTTPClient: o.httpClient},
+		Flakes:           cfg.Flakes,
+		Escalation:       cfg.Escalation,
+		Storm:            cfg.Storm,
+		Audit:            auditor,
+		Breakages:        breakages,
+		AutoGroup:        autoGroup,
+		State:            store,
+		HTTPClient:       o.httpClient,
+	}, webhooks, nil
+}
+
+// readOnlyState loads the groomer state without saving changes to it.
+type readOnlyState struct {
+	status_groomer.StateStore
+}
+
+func (readOnlyState) Save(status_groomer.State) error { return nil }
+
+// runDry runs a single pass against a dry run backend and prints the plan.
+func (o *options) runDry(groomer *status_groomer.Groomer) error {
+	plan := status_groomer.NewDryRunBackend(groomer.Backend)
+	groomer.Backend = plan
+	groomer.Notifiers = nil
+	groomer.Audit = nil
+	groomer.Breakages = nil
+	if groomer.State != nil {
+		groomer.State = readOnlyState{groomer.State}
+	}
+	if err := groomer.Cycle(); err != nil {
+		return err
+	}
+	if o.planFormat == "json" {
+		return plan.Plan.WriteJSON(os.Stdout)
+	}
+	return plan.Plan.WriteText(os.Stdout)
+}
+
+func (o *options) logger() (logging.Logger, error) {
+	level, err := logging.ParseLevel(o.logLevel)
+	if err != nil {
+		return nil, err
+	}
+	format, err := logging.ParseFormat(o.logFormat)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(os.Stdout, format, level), nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func issueBackend(name string, httpConfig tracker.HTTPConfig, jiraConfigFile string, httpClient *http.Client) (status_groomer.IssueBackend, error) {
+	switch name {
+	case "", "tracker":
+		trackerProjectID, err := strconv.Atoi(os.Getenv("TRACKER_PROJECT_ID"))
+		if err != nil {
+			return nil, err
+		}
+		client := tracker.Client{
+			APIToken:   os.Getenv("TRACKER_API_TOKEN"),
+			TrackerAPI: "https://www.pivotaltracker.com/services/v5",
+			HTTPClient: tracker.NewHTTPClient(httpConfig),
+		}
+		return status_groomer.TrackerBackend{Client: client, ProjectID: trackerProjectID}, nil
+	case "github":
+		repo := strings.SplitN(os.Getenv("GITHUB_REPOSITORY"), "/", 2)
+		if len(repo) != 2 {
+			return nil, fmt.Errorf("GITHUB_REPOSITORY must be owner/repo, got %q", os.Getenv("GITHUB_REPOSITORY"))
+		}
+		api := os.Getenv("GITHUB_API_URL")
+		if api == "" {
+			api = "https://api.github.com"
+		}
+		return github.Client{
+			GitHubAPI:  api,
+			Token:      os.Getenv("GITHUB_TOKEN"),
+			Owner:      repo[0],
+			Repo:       repo[1],
+			HTTPClient: httpClient,
+		}, nil
+	case "jira":
+		config, err := loadJiraConfig(jiraConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		return jira.Client{
+			Config:     config,
+			Token:      os.Getenv("JIRA_API_TOKEN"),
+			HTTPClient: httpClient,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown issue backend %q", name)
+}
+
+func loadJiraConfig(path string) (jira.Config, error) {
+	var config jira.Config
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return config, err
+	}
+
+	// stories are looked up across every configured project
+	if config.Key != "" {
+		return config, nil
+	}
+	for _, group := range config.Groups {
+		if group.Key != "" {
+			return config, nil
+		}
+	}
+	return config, errors.New("no Jira project is configured, set project at the top level or for a group")
+}
+
+// flushEvery retries queued webhook deliveries every interval. It returns
+// right away when no webhooks are configured.
+func flushEvery(webhooks *notifier.Webhooks, interval time.Duration) {
+	if webhooks == nil {
+		return
+	}
+	for range time.Tick(interval) {
+		webhooks.Flush()
+	}
 }
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+const namespace = "concourse_tracker_bot_"
+
+// Metrics are the bot's own metrics. It is both a notifier and an observer of
+// the groomer so that it sees every cycle and every change to a story.
+type Metrics struct {
+	Registry *Registry
+
+	cycleDuration   *Histogram
+	jobsChecked     *Counter
+	failingJobs     *Gauge
+	events          *Counter
+	requestDuration *Histogram
+	requests        *Counter
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+	m := &Metrics{
+		Registry:        r,
+		cycleDuration:   r.Histogram(namespace+"cycle_duration_seconds", "Time taken to poll every job.", DefaultBuckets, "result"),
+		jobsChecked:     r.Counter(namespace+"jobs_checked_total", "Jobs whose latest build was checked."),
+		failingJobs:     r.Gauge(namespace+"failing_jobs", "Jobs whose latest build did not succeed at the last poll.", "status", "group"),
+		events:          r.Counter(namespace+"events_total", "Stories created, commented on, resolved and other groomer events.", "type"),
+		requestDuration: r.Histogram(namespace+"http_request_duration_seconds", "Latency of requests to Concourse and the issue tracker.", DefaultBuckets, "service", "code"),
+		requests:        r.Counter(namespace+"http_requests_total", "Requests to Concourse and the issue tracker by status code.", "service", "code"),
+	}
+	r.GaugeFunc(namespace+"last_successful_cycle_timestamp_seconds", "Unix time of the last poll that succeeded.", func() float64 {
+		last := m.LastSuccess()
+		if last.IsZero() {
+			return 0
+		}
+		return float64(last.UnixNano()) / 1e9
+	})
+	r.GaugeFunc(namespace+"seconds_since_last_successful_cycle", "Time since the last poll that succeeded, or -1 before the first one.", func() float64 {
+		last := m.LastSuccess()
+		if last.IsZero() {
+			return -1
+		}
+		return time.Since(last).Seconds()
+	})
+	return m
+}
+
+func (m *Metrics) CycleFinished(stats status_groomer.CycleStats) {
+	result := "success"
+	if stats.Err != nil {
+		result = "failure"
+	}
+	m.cycleDuration.Observe(stats.Duration.Seconds(), result)
+	m.jobsChecked.Add(float64(stats.Jobs))
+	if stats.Err != nil {
+		return
+	}
+
+	m.failingJobs.Reset()
+	for failure, count := range stats.Failures {
+		group := failure.Group
+		if group == "" {
+			group = "ungrouped"
+		}
+		m.failingJobs.Set(float64(count), failure.Status, group)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSuccess = stats.Started.Add(stats.Duration)
+}
+
+func (m *Metrics) Notify(event status_groomer.Event) error {
+	m.events.Inc(event.Type)
+	return nil
+}
+
+func (m *Metrics) RequestFinished(service, code string, duration time.Duration) {
+	m.requestDuration.Observe(duration.Seconds(), service, code)
+	m.requests.Inc(service, code)
+}
+
+func (m *Metrics) LastSuccess() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastSuccess
+}
@@ -0,0 +1,111 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/metrics"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metrics", func() {
+	var m *metrics.Metrics
+
+	BeforeEach(func() {
+		m = metrics.New()
+	})
+
+	scrape := func() string {
+		var out bytes.Buffer
+		m.Registry.Write(&out)
+		return out.String()
+	}
+
+	It("records each cycle", func() {
+		m.CycleFinished(status_groomer.CycleStats{
+			Started:  time.Now(),
+			Duration: 2 * time.Second,
+			Jobs:     3,
+			Failures: map[status_groomer.Failure]int{
+				{Status: "failed", Group: "luna"}: 2,
+				{Status: "errored"}:               1,
+			},
+		})
+
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_cycle_duration_seconds_sum{result="success"} 2` + "\n"))
+		Expect(scrape()).To(ContainSubstring("concourse_tracker_bot_jobs_checked_total 3\n"))
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_failing_jobs{status="failed",group="luna"} 2` + "\n"))
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_failing_jobs{status="errored",group="ungrouped"} 1` + "\n"))
+		Expect(m.LastSuccess()).NotTo(BeZero())
+	})
+
+	It("keeps the last success and failing jobs when a cycle fails", func() {
+		Expect(scrape()).To(ContainSubstring("concourse_tracker_bot_seconds_since_last_successful_cycle -1\n"))
+
+		m.CycleFinished(status_groomer.CycleStats{Started: time.Now(), Failures: map[status_groomer.Failure]int{{Status: "failed"}: 1}})
+		last := m.LastSuccess()
+		m.CycleFinished(status_groomer.CycleStats{Started: time.Now(), Err: errors.New("concourse is down")})
+
+		Expect(m.LastSuccess()).To(Equal(last))
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_cycle_duration_seconds_count{result="failure"} 1` + "\n"))
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_failing_jobs{status="failed",group="ungrouped"} 1` + "\n"))
+		Expect(scrape()).NotTo(ContainSubstring("concourse_tracker_bot_seconds_since_last_successful_cycle -1\n"))
+	})
+
+	It("counts groomer events by type", func() {
+		Expect(m.Notify(status_groomer.Event{Type: status_groomer.StoryCreated})).To(Succeed())
+		Expect(m.Notify(status_groomer.Event{Type: status_groomer.CommentAdded})).To(Succeed())
+		Expect(m.Notify(status_groomer.Event{Type: status_groomer.CommentAdded})).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_events_total{type="comment_added"} 2` + "\n"))
+		Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_events_total{type="story_created"} 1` + "\n"))
+	})
+
+	Describe("Transport", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/missing" {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("records requests by service and status code", func() {
+			client := &http.Client{Transport: metrics.Transport{
+				Metrics:  m,
+				Services: map[string]string{strings.TrimPrefix(server.URL, "http://"): "concourse"},
+			}}
+
+			_, err := client.Get(server.URL + "/api/v1/jobs")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Get(server.URL + "/missing")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_http_requests_total{service="concourse",code="200"} 1` + "\n"))
+			Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_http_requests_total{service="concourse",code="404"} 1` + "\n"))
+			Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_http_request_duration_seconds_count{service="concourse",code="200"} 1` + "\n"))
+		})
+
+		It("records requests that get no response as errors", func() {
+			client := &http.Client{Transport: metrics.Transport{Metrics: m}}
+			server.Close()
+
+			_, err := client.Get(server.URL)
+			Expect(err).To(HaveOccurred())
+			Expect(scrape()).To(ContainSubstring(`concourse_tracker_bot_http_requests_total{service="other",code="error"} 1` + "\n"))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets, in seconds, used for latencies.
+var DefaultBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// Registry holds metrics and serves them in the Prometheus text format.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{series: newSeries(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{series: newSeries(name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+// GaugeFunc registers a gauge whose value is read from f on every scrape.
+func (r *Registry) GaugeFunc(name, help string, f func() float64) {
+	r.register(gaugeFunc{name: name, help: help, f: f})
+}
+
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{series: newSeries(name, help, "histogram", labels), buckets: buckets}
+	r.register(h)
+	return h
+}
+
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	metrics := append([]metric{}, r.metrics...)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.Write(w)
+}
+
+type series struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	kind   string
+	labels []string
+	values map[string]*value
+}
+
+type value struct {
+	labels  string
+	value   float64
+	sum     float64
+	buckets []uint64
+}
+
+func newSeries(name, help, kind string, labels []string) series {
+	return series{name: name, help: help, kind: kind, labels: labels, values: map[string]*value{}}
+}
+
+// get returns the value for labelValues; callers must hold s.mu.
+func (s *series) get(labelValues []string) *value {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("%s has %d labels, got %d values", s.name, len(s.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	v, ok := s.values[key]
+	if !ok {
+		v = &value{labels: formatLabels(s.labels, labelValues)}
+		s.values[key] = v
+	}
+	return v
+}
+
+func (s *series) sorted() []*value {
+	values := make([]*value, 0, len(s.values))
+	for _, v := range s.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].labels < values[j].labels })
+	return values
+}
+
+func (s *series) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, s.kind)
+}
+
+func (s *series) write(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.header(w)
+	for _, v := range s.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", s.name, braces(v.labels), formatFloat(v.value))
+	}
+}
+
+type Counter struct{ series }
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(labelValues).value += delta
+}
+
+type Gauge struct{ series }
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(labelValues).value = v
+}
+
+// Reset forgets every label combination, e.g. before setting the gauge from
+// a fresh snapshot.
+func (g *Gauge) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values = map[string]*value{}
+}
+
+type gaugeFunc struct {
+	name string
+	help string
+	f    func() float64
+}
+
+func (g gaugeFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.name, g.help, g.name, g.name, formatFloat(g.f()))
+}
+
+type Histogram struct {
+	series
+	buckets []float64
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	val := h.get(labelValues)
+	if val.buckets == nil {
+		val.buckets = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			val.buckets[i]++
+		}
+	}
+	val.value++
+	val.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w)
+	for _, v := range h.sorted() {
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, braces(join(v.labels, `le="`+formatFloat(bound)+`"`)), v.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %s\n", h.name, braces(join(v.labels, `le="+Inf"`)), formatFloat(v.value))
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, braces(v.labels), formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %s\n", h.name, braces(v.labels), formatFloat(v.value))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func join(labels, label string) string {
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
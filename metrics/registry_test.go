@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jaresty/concourse-tracker-bot/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var registry *metrics.Registry
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+	})
+
+	scrape := func() string {
+		var out bytes.Buffer
+		registry.Write(&out)
+		return out.String()
+	}
+
+	It("writes counters and gauges in the Prometheus text format", func() {
+		counter := registry.Counter("stories_total", "Stories filed.", "group")
+		counter.Inc("luna")
+		counter.Add(2, `say "hi"`)
+		registry.Gauge("jobs", "Jobs watched.").Set(3)
+
+		Expect(scrape()).To(Equal(`# HELP stories_total Stories filed.
+# TYPE stories_total counter
+stories_total{group="luna"} 1
+stories_total{group="say \"hi\""} 2
+# HELP jobs Jobs watched.
+# TYPE jobs gauge
+jobs 3
+`))
+	})
+
+	It("writes cumulative histogram buckets", func() {
+		histogram := registry.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "code")
+		histogram.Observe(0.05, "200")
+		histogram.Observe(0.5, "200")
+
+		Expect(scrape()).To(Equal(`# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{code="200",le="0.1"} 1
+latency_seconds_bucket{code="200",le="1"} 2
+latency_seconds_bucket{code="200",le="+Inf"} 2
+latency_seconds_sum{code="200"} 0.55
+latency_seconds_count{code="200"} 2
+`))
+	})
+
+	It("forgets label combinations when a gauge is reset", func() {
+		gauge := registry.Gauge("failing_jobs", "Failing jobs.", "group")
+		gauge.Set(1, "luna")
+		gauge.Reset()
+		gauge.Set(2, "snitch")
+
+		Expect(scrape()).NotTo(ContainSubstring("luna"))
+		Expect(scrape()).To(ContainSubstring(`failing_jobs{group="snitch"} 2`))
+	})
+
+	It("serves the metrics over HTTP", func() {
+		registry.GaugeFunc("up", "Whether the bot is up.", func() float64 { return 1 })
+
+		recorder := httptest.NewRecorder()
+		registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain"))
+		Expect(recorder.Body.String()).To(ContainSubstring("\nup 1\n"))
+	})
+})
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Transport records the latency and status code of every request it sends.
+// Requests that get no response are recorded with the code "error".
+type Transport struct {
+	Base     http.RoundTripper
+	Metrics  *Metrics
+	Services map[string]string
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	started := time.Now()
+	res, err := base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	t.Metrics.RequestFinished(t.service(req), code, time.Since(started))
+	return res, err
+}
+
+func (t Transport) service(req *http.Request) string {
+	if service, ok := t.Services[req.URL.Host]; ok {
+		return service
+	}
+	return "other"
+}
@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       string          `json:"event"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+
+	file string
+}
+
+// Queue keeps undelivered webhooks on disk, one file per delivery, so that
+// they survive a restart. Without a Dir they are kept in memory instead. Once
+// Max deliveries are queued the oldest is dropped to make room.
+type Queue struct {
+	Dir string
+	Max int
+
+	memory []Delivery
+}
+
+func (q *Queue) max() int {
+	if q.Max > 0 {
+		return q.Max
+	}
+	return 1000
+}
+
+func (q *Queue) Push(d Delivery) error {
+	if q.Dir == "" {
+		q.memory = append(q.memory, d)
+		if len(q.memory) > q.max() {
+			q.memory = q.memory[len(q.memory)-q.max():]
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(q.Dir, 0700); err != nil {
+		return err
+	}
+
+	pending, err := q.Pending()
+	if err != nil {
+		return err
+	}
+	for i := 0; i <= len(pending)-q.max(); i++ {
+		q.Remove(pending[i])
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	// write then rename so that a crash never leaves half a delivery behind
+	name := filepath.Join(q.Dir, d.ID+".json")
+	if err := ioutil.WriteFile(name+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// Pending returns the queued deliveries, oldest first.
+func (q *Queue) Pending() ([]Delivery, error) {
+	if q.Dir == "" {
+		return append([]Delivery{}, q.memory...), nil
+	}
+
+	files, err := ioutil.ReadDir(q.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	deliveries := []Delivery{}
+	for _, name := range names {
+		path := filepath.Join(q.Dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			os.Remove(path)
+			continue
+		}
+		d.file = path
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+func (q *Queue) Remove(d Delivery) error {
+	if q.Dir == "" {
+		for i, queued := range q.memory {
+			if queued.ID == d.ID {
+				q.memory = append(q.memory[:i], q.memory[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+
+	if d.file == "" {
+		d.file = filepath.Join(q.Dir, d.ID+".json")
+	}
+	return os.Remove(d.file)
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type Channel struct {
+	WebhookURL string `yaml:"webhook_url"`
+	Channel    string `yaml:"channel"`
+}
+
+// Slack posts to Slack-compatible incoming webhooks. Groups without a
+// channel of their own are announced on the default channel.
+type Slack struct {
+	Channel    `yaml:",inline"`
+	Groups     map[string]Channel `yaml:"groups"`
+	HTTPClient *http.Client       `yaml:"-"`
+}
+
+var defaultSlackClient = &http.Client{Timeout: 10 * time.Second}
+
+type message struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// Configured reports whether any channel has a webhook to post to.
+func (s Slack) Configured() bool {
+	if s.WebhookURL != "" {
+		return true
+	}
+	for _, c := range s.Groups {
+		if c.WebhookURL != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Slack) channel(group string) Channel {
+	if c, ok := s.Groups[group]; ok && c.WebhookURL != "" {
+		return c
+	}
+	if c, ok := s.Groups[group]; ok && c.Channel != "" {
+		return Channel{WebhookURL: s.WebhookURL, Channel: c.Channel}
+	}
+	return s.Channel
+}
+
+func (s Slack) Notify(event status_groomer.Event) error {
+	channel := s.channel(event.Group)
+	if _, ok := headlines[event.Type]; !ok || channel.WebhookURL == "" {
+		return nil
+	}
+
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(message{
+		Text:    format(event),
+		Channel: channel.Channel,
+	}); err != nil {
+		return err
+	}
+
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultSlackClient
+	}
+
+	resp, err := httpClient.Post(channel.WebhookURL, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+var headlines = map[string]string{
+	status_groomer.StoryCreated:   ":red_circle: %s",
+	status_groomer.StoryReopened:  ":red_circle: %s again",
+	status_groomer.CommentAdded:   ":repeat: %s again",
+	status_groomer.StoryResolved:  ":large_green_circle: %s has recovered",
+	status_groomer.StoryEscalated: ":rotating_light: %s and is still red",
+}
+
+func format(event status_groomer.Event) string {
+	subject := event.Title
+	if event.Type == status_groomer.StoryResolved {
+		subject = strings.TrimSuffix(subject, " has failed")
+	}
+
+	lines := []string{fmt.Sprintf(headlines[event.Type], escape(subject))}
+	details := []string{}
+	if event.IssueURL != "" {
+		details = append(details, fmt.Sprintf("<%s|story>", event.IssueURL))
+	}
+	if event.BuildURL != "" {
+		details = append(details, fmt.Sprintf("<%s|%s/%s>", event.BuildURL, escape(event.Pipeline), escape(event.Job)))
+	}
+	if event.FailingStep != "" {
+		details = append(details, fmt.Sprintf("failing step `%s`", escape(event.FailingStep)))
+	}
+	if event.FailingSince != nil {
+		details = append(details, fmt.Sprintf("red for %dh", int(time.Since(*event.FailingSince).Hours())))
+	}
+	if len(details) > 0 {
+		lines = append(lines, strings.Join(details, " · "))
+	}
+	if len(event.Owners) > 0 {
+		lines = append(lines, "cc "+strings.Join(event.Owners, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
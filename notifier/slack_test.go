@@ -0,0 +1,153 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/notifier"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type posted struct {
+	Path    string
+	Text    string `json:"text"`
+	Channel string `json:"channel"`
+}
+
+var _ = Describe("Slack", func() {
+	var (
+		ts       *httptest.Server
+		messages []posted
+		slack    notifier.Slack
+		event    status_groomer.Event
+	)
+
+	BeforeEach(func() {
+		messages = []posted{}
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/broken" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("no_service"))
+				return
+			}
+
+			var m posted
+			json.NewDecoder(r.Body).Decode(&m)
+			m.Path = r.URL.Path
+			messages = append(messages, m)
+			w.Write([]byte("ok"))
+		}))
+
+		slack = notifier.Slack{
+			Channel: notifier.Channel{WebhookURL: ts.URL + "/default"},
+			Groups: map[string]notifier.Channel{
+				"luna":   {WebhookURL: ts.URL + "/luna"},
+				"snitch": {Channel: "#snitch"},
+				"broken": {WebhookURL: ts.URL + "/broken"},
+			},
+		}
+
+		event = status_groomer.Event{
+			Type:        status_groomer.StoryCreated,
+			Group:       "luna",
+			Title:       "luna has failed",
+			IssueURL:    "https://www.pivotaltracker.com/story/show/2",
+			Pipeline:    "cf-deployment",
+			Job:         "fresh-deploy",
+			BuildURL:    "https://ci/builds/1",
+			FailingStep: "run-cats",
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("posts the story link, build link and failing step to the group's webhook", func() {
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages).To(Equal([]posted{{
+			Path: "/luna",
+			Text: ":red_circle: luna has failed\n<https://www.pivotaltracker.com/story/show/2|story> · <https://ci/builds/1|cf-deployment/fresh-deploy> · failing step `run-cats`",
+		}}))
+	})
+
+	It("announces recoveries", func() {
+		event.Type = status_groomer.StoryResolved
+		event.FailingStep = ""
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Text).To(HavePrefix(":large_green_circle: luna has recovered\n"))
+	})
+
+	It("announces escalations to the owners", func() {
+		since := time.Now().Add(-30*time.Hour - time.Minute)
+		event.Type = status_groomer.StoryEscalated
+		event.FailingStep = ""
+		event.FailingSince = &since
+		event.Owners = []string{"<!subteam^S123>", "<@U456>"}
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Text).To(Equal(":rotating_light: luna has failed and is still red\n<https://www.pivotaltracker.com/story/show/2|story> · <https://ci/builds/1|cf-deployment/fresh-deploy> · red for 30h\ncc <!subteam^S123> <@U456>"))
+	})
+
+	It("announces further failures on an existing story", func() {
+		event.Type = status_groomer.CommentAdded
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Text).To(HavePrefix(":repeat: luna has failed again\n"))
+	})
+
+	It("overrides the channel on the default webhook", func() {
+		event.Group = "snitch"
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Path).To(Equal("/default"))
+		Expect(messages[0].Channel).To(Equal("#snitch"))
+	})
+
+	It("falls back to the default webhook for other groups", func() {
+		event.Group = ""
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Path).To(Equal("/default"))
+		Expect(messages[0].Channel).To(BeEmpty())
+	})
+
+	It("only announces changes to stories", func() {
+		event.Type = status_groomer.FailureIgnored
+		Expect(slack.Notify(event)).To(Succeed())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("does nothing when no webhook is configured", func() {
+		Expect(notifier.Slack{}.Notify(event)).To(Succeed())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("is configured when any channel has a webhook", func() {
+		Expect(notifier.Slack{}.Configured()).To(BeFalse())
+		Expect(notifier.Slack{Groups: map[string]notifier.Channel{"groupa": {Channel: "#a"}}}.Configured()).To(BeFalse())
+		Expect(notifier.Slack{Groups: map[string]notifier.Channel{"groupa": {WebhookURL: "https://hooks/a"}}}.Configured()).To(BeTrue())
+		Expect(slack.Configured()).To(BeTrue())
+	})
+
+	It("escapes Slack control characters", func() {
+		event.Title = "<pipeline> & friends has failed"
+		Expect(slack.Notify(event)).To(Succeed())
+
+		Expect(messages[0].Text).To(HavePrefix(":red_circle: &lt;pipeline&gt; &amp; friends has failed\n"))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the webhook rejects the message", func() {
+			event.Group = "broken"
+			Expect(slack.Notify(event)).To(MatchError("404 Not Found - no_service"))
+		})
+	})
+})
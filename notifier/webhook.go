@@ -0,0 +1,204 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+const (
+	EventHeader     = "X-Concourse-Tracker-Bot-Event"
+	DeliveryHeader  = "X-Concourse-Tracker-Bot-Delivery"
+	SignatureHeader = "X-Concourse-Tracker-Bot-Signature-256"
+)
+
+type Endpoint struct {
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"`
+}
+
+func (e Endpoint) wants(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookConfig struct {
+	Endpoints   []Endpoint `yaml:"endpoints"`
+	QueueDir    string     `yaml:"queue_dir"`
+	MaxQueued   int        `yaml:"max_queued"`
+	MaxAttempts int        `yaml:"max_attempts"`
+}
+
+// Webhooks POSTs every groomer event as signed JSON to each endpoint.
+// Deliveries that fail are queued, on disk when QueueDir is set and in memory
+// otherwise, and retried with exponential backoff whenever the queue is
+// flushed.
+type Webhooks struct {
+	config     WebhookConfig
+	queue      *Queue
+	httpClient *http.Client
+	mu         sync.Mutex
+}
+
+func NewWebhooks(config WebhookConfig, httpClient *http.Client) *Webhooks {
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = 10
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Webhooks{
+		config:     config,
+		queue:      &Queue{Dir: config.QueueDir, Max: config.MaxQueued},
+		httpClient: httpClient,
+	}
+}
+
+// Sign returns the signature header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Webhooks) Notify(event status_groomer.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flush()
+
+	var failed []error
+	for i, endpoint := range w.config.Endpoints {
+		if !endpoint.wants(event.Type) {
+			continue
+		}
+
+		d := Delivery{
+			ID:       fmt.Sprintf("%d-%d", time.Now().UnixNano(), i),
+			URL:      endpoint.URL,
+			Event:    event.Type,
+			Body:     body,
+			Attempts: 1,
+		}
+		if err := w.deliver(endpoint, d); err != nil {
+			failed = append(failed, w.requeue(d, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return failed[0]
+	}
+	return nil
+}
+
+// Flush retries every queued delivery whose backoff has elapsed.
+func (w *Webhooks) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+}
+
+func (w *Webhooks) flush() {
+	pending, err := w.queue.Pending()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, d := range pending {
+		if d.NextAttempt.After(now) {
+			continue
+		}
+
+		endpoint, ok := w.endpoint(d.URL)
+		if !ok {
+			w.queue.Remove(d)
+			continue
+		}
+
+		d.Attempts++
+		err := w.deliver(endpoint, d)
+		w.queue.Remove(d)
+		if err != nil {
+			w.requeue(d, err)
+		}
+	}
+}
+
+func (w *Webhooks) endpoint(url string) (Endpoint, bool) {
+	for _, e := range w.config.Endpoints {
+		if e.URL == url {
+			return e, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+func (w *Webhooks) requeue(d Delivery, cause error) error {
+	if d.Attempts >= w.config.MaxAttempts {
+		return fmt.Errorf("giving up on %s to %s after %d attempts: %s", d.Event, d.URL, d.Attempts, cause)
+	}
+
+	d.NextAttempt = time.Now().Add(backoff(d.Attempts))
+	if err := w.queue.Push(d); err != nil {
+		return fmt.Errorf("delivering %s to %s: %s (and could not queue it: %s)", d.Event, d.URL, cause, err)
+	}
+	return fmt.Errorf("delivering %s to %s: %s (queued for retry)", d.Event, d.URL, cause)
+}
+
+func backoff(attempts int) time.Duration {
+	wait := 30 * time.Second << uint(attempts-1)
+	if wait > time.Hour || wait <= 0 {
+		return time.Hour
+	}
+	return wait
+}
+
+func (w *Webhooks) deliver(endpoint Endpoint, d Delivery) error {
+	req, err := http.NewRequest("POST", endpoint.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, d.Body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s - %s", resp.Status, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package notifier_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/notifier"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type received struct {
+	Path      string
+	Event     string
+	Delivery  string
+	Signature string
+	Body      []byte
+}
+
+var _ = Describe("Webhooks", func() {
+	var (
+		ts       *httptest.Server
+		requests []received
+		down     bool
+		queueDir string
+		config   notifier.WebhookConfig
+		event    status_groomer.Event
+	)
+
+	// makeDue moves every queued delivery's next attempt into the past.
+	makeDue := func() {
+		queue := &notifier.Queue{Dir: queueDir}
+		pending, err := queue.Pending()
+		Expect(err).NotTo(HaveOccurred())
+		for _, d := range pending {
+			Expect(queue.Remove(d)).To(Succeed())
+			d.NextAttempt = time.Now().Add(-time.Second)
+			Expect(queue.Push(d)).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		requests = []received{}
+		down = false
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if down {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, received{
+				Path:      r.URL.Path,
+				Event:     r.Header.Get(notifier.EventHeader),
+				Delivery:  r.Header.Get(notifier.DeliveryHeader),
+				Signature: r.Header.Get(notifier.SignatureHeader),
+				Body:      body,
+			})
+		}))
+
+		var err error
+		queueDir, err = ioutil.TempDir("", "webhooks")
+		Expect(err).NotTo(HaveOccurred())
+
+		config = notifier.WebhookConfig{
+			Endpoints: []notifier.Endpoint{
+				{URL: ts.URL + "/all", Secret: "shh"},
+				{URL: ts.URL + "/resolved", Events: []string{status_groomer.StoryResolved}},
+			},
+			QueueDir: queueDir,
+		}
+
+		event = status_groomer.Event{
+			Type:     status_groomer.StoryCreated,
+			Time:     time.Date(2017, 9, 1, 12, 0, 0, 0, time.UTC),
+			Group:    "luna",
+			Title:    "luna has failed",
+			IssueID:  "7",
+			BuildURL: "https://ci/builds/1",
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+		os.RemoveAll(queueDir)
+	})
+
+	It("posts the event as JSON signed with the endpoint's secret", func() {
+		Expect(notifier.NewWebhooks(config, nil).Notify(event)).To(Succeed())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Path).To(Equal("/all"))
+		Expect(requests[0].Event).To(Equal("story_created"))
+		Expect(requests[0].Delivery).NotTo(BeEmpty())
+		Expect(requests[0].Body).To(MatchJSON(`{
+			"type": "story_created",
+			"time": "2017-09-01T12:00:00Z",
+			"group": "luna",
+			"title": "luna has failed",
+			"issue_id": "7",
+			"build_url": "https://ci/builds/1"
+		}`))
+		Expect(requests[0].Signature).To(Equal(notifier.Sign("shh", requests[0].Body)))
+		Expect(requests[0].Signature).To(HavePrefix("sha256="))
+	})
+
+	It("only sends the events an endpoint subscribed to", func() {
+		event.Type = status_groomer.StoryResolved
+		Expect(notifier.NewWebhooks(config, nil).Notify(event)).To(Succeed())
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[1].Path).To(Equal("/resolved"))
+		Expect(requests[1].Signature).To(BeEmpty())
+	})
+
+	Context("when an endpoint is unavailable", func() {
+		var webhooks *notifier.Webhooks
+
+		BeforeEach(func() {
+			webhooks = notifier.NewWebhooks(config, nil)
+			down = true
+			Expect(webhooks.Notify(event)).To(MatchError(ContainSubstring("queued for retry")))
+			down = false
+		})
+
+		It("queues the delivery on disk until it is due", func() {
+			pending, err := (&notifier.Queue{Dir: queueDir}).Pending()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].Attempts).To(Equal(1))
+			Expect(pending[0].NextAttempt).To(BeTemporally(">", time.Now()))
+
+			webhooks.Flush()
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("retries the delivery once it is due, even after a restart", func() {
+			makeDue()
+			notifier.NewWebhooks(config, nil).Flush()
+
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Signature).To(Equal(notifier.Sign("shh", requests[0].Body)))
+
+			pending, err := (&notifier.Queue{Dir: queueDir}).Pending()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+
+		It("gives up after the maximum number of attempts", func() {
+			config.MaxAttempts = 2
+			webhooks = notifier.NewWebhooks(config, nil)
+			down = true
+
+			makeDue()
+			webhooks.Flush()
+
+			pending, err := (&notifier.Queue{Dir: queueDir}).Pending()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+	})
+
+	It("queues failed deliveries in memory without a queue dir", func() {
+		config.QueueDir = ""
+		down = true
+
+		webhooks := notifier.NewWebhooks(config, nil)
+		err := webhooks.Notify(event)
+		Expect(err).To(MatchError(ContainSubstring("delivering story_created to " + ts.URL + "/all: 503 Service Unavailable")))
+		Expect(err).To(MatchError(ContainSubstring("(queued for retry)")))
+
+		down = false
+		webhooks.Flush()
+		Expect(requests).To(BeEmpty())
+		Expect(ioutil.ReadDir(queueDir)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Queue", func() {
+	var queue *notifier.Queue
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "queue")
+		Expect(err).NotTo(HaveOccurred())
+		queue = &notifier.Queue{Dir: dir, Max: 2}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(queue.Dir)
+	})
+
+	It("drops the oldest delivery once full", func() {
+		for _, id := range []string{"1", "2", "3"} {
+			Expect(queue.Push(notifier.Delivery{ID: id, Body: json.RawMessage(`{}`)})).To(Succeed())
+		}
+
+		pending, err := queue.Pending()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(HaveLen(2))
+		Expect(pending[0].ID).To(Equal("2"))
+		Expect(pending[1].ID).To(Equal("3"))
+	})
+
+	It("keeps deliveries in memory without a dir", func() {
+		memory := &notifier.Queue{Max: 2}
+		for _, id := range []string{"1", "2", "3"} {
+			Expect(memory.Push(notifier.Delivery{ID: id})).To(Succeed())
+		}
+		Expect(memory.Remove(notifier.Delivery{ID: "2"})).To(Succeed())
+
+		pending, err := memory.Pending()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(HaveLen(1))
+		Expect(pending[0].ID).To(Equal("3"))
+	})
+
+	It("is empty before anything has been queued", func() {
+		queue.Dir = queue.Dir + "/missing"
+		Expect(queue.Pending()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+)
+
+func onceCommand(args []string) error {
+	var opts options
+	flags := flag.NewFlagSet("once", flag.ExitOnError)
+	opts.register(flags)
+	flags.Parse(args)
+
+	log, err := opts.logger()
+	if err != nil {
+		return err
+	}
+	groomer, webhooks, err := opts.groomer(log)
+	if err != nil {
+		return err
+	}
+	if opts.dryRun {
+		return opts.runDry(groomer)
+	}
+
+	err = groomer.Cycle()
+	if webhooks != nil {
+		webhooks.Flush()
+	}
+	return err
+}
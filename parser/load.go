@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Load reads a group config file mapping group names to job name patterns.
+func Load(path string) (map[string][]string, error) {
+	return Read(File(path))
+}
+
+// Read fetches a group config from src.
+func Read(src Source) (map[string][]string, error) {
+	data, err := src.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
+
+func Unmarshal(data []byte) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if err := yaml.UnmarshalStrict(data, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Validate checks that every pattern of every group compiles.
+func Validate(inputMap map[string][]string) error {
+	groups := make([]string, 0, len(inputMap))
+	for group := range inputMap {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		if len(inputMap[group]) == 0 {
+			return fmt.Errorf("group %s has no patterns", group)
+		}
+		for _, pattern := range inputMap[group] {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("group %s: %s", group, err)
+			}
+		}
+	}
+	return nil
+}
+
+type Match struct {
+	Group   string
+	Pattern string
+}
+
+// Matches lists every group pattern that matches a "<pipeline>-<job>" name,
+// ordered by group.
+func Matches(inputMap map[string][]string, name string) []Match {
+	matches := []Match{}
+	for group, patterns := range inputMap {
+		for _, pattern := range patterns {
+			if matched, err := regexp.MatchString(pattern, name); err == nil && matched {
+				matches = append(matches, Match{Group: group, Pattern: pattern})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Group != matches[j].Group {
+			return matches[i].Group < matches[j].Group
+		}
+		return matches[i].Pattern < matches[j].Pattern
+	})
+	return matches
+}
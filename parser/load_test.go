@@ -0,0 +1,74 @@
+package parser_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/jaresty/concourse-tracker-bot/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "groups")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads the group config file", func() {
+		path := filepath.Join(dir, "groups.yml")
+		Expect(ioutil.WriteFile(path, []byte("luna:\n- cf-deployment-.*-fresh\n"), 0644)).To(Succeed())
+
+		groups, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(groups).To(Equal(map[string][]string{"luna": {"cf-deployment-.*-fresh"}}))
+	})
+
+	It("rejects files that are not a map of groups to patterns", func() {
+		path := filepath.Join(dir, "groups.yml")
+		Expect(ioutil.WriteFile(path, []byte("luna: cf-deployment-.*-fresh\n"), 0644)).To(Succeed())
+
+		_, err := Load(path)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("accepts groups whose patterns compile", func() {
+		Expect(Validate(map[string][]string{"luna": {"cf-.*-fresh"}})).To(Succeed())
+	})
+
+	It("names the group with a broken pattern", func() {
+		err := Validate(map[string][]string{"luna": {"cf-.*-fresh"}, "snitch": {"cf-(lite"}})
+		Expect(err).To(MatchError(ContainSubstring("group snitch: error parsing regexp")))
+	})
+
+	It("rejects groups without patterns", func() {
+		Expect(Validate(map[string][]string{"luna": {}})).To(MatchError("group luna has no patterns"))
+	})
+})
+
+var _ = Describe("Matches", func() {
+	It("lists every matching group pattern", func() {
+		groups := map[string][]string{
+			"luna":   {"cf-deployment-.*-fresh", "cf-deployment-fresh-.*"},
+			"snitch": {"cf-deployment-.*-lite"},
+			"all":    {"cf-deployment-.*"},
+		}
+
+		Expect(Matches(groups, "cf-deployment-deploy-fresh")).To(Equal([]Match{
+			{Group: "all", Pattern: "cf-deployment-.*"},
+			{Group: "luna", Pattern: "cf-deployment-.*-fresh"},
+		}))
+		Expect(Matches(groups, "cf-release-deploy")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source fetches the raw group config.
+type Source interface {
+	Fetch() ([]byte, error)
+	String() string
+}
+
+// NewSource returns the source for a --group-config-file value: an HTTP(S)
+// URL, a file read at ref from the git working copy it is in, or a plain file
+// when ref is empty.
+func NewSource(location, ref string) Source {
+	switch {
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+		return &URL{URL: location}
+	case ref != "":
+		return Git{Path: location, Ref: ref}
+	}
+	return File(location)
+}
+
+type File string
+
+func (f File) Fetch() ([]byte, error) { return ioutil.ReadFile(string(f)) }
+func (f File) String() string         { return string(f) }
+
+// URL fetches the config over HTTP, asking the server whether it changed
+// since the last fetch with the ETag it returned. Without a Client, a fetch
+// gives up after 30 seconds so that the last good config can be used.
+type URL struct {
+	URL    string
+	Client *http.Client
+
+	mu   sync.Mutex
+	etag string
+	body []byte
+}
+
+func (u *URL) Fetch() ([]byte, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	req, err := http.NewRequest("GET", u.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if u.etag != "" {
+		req.Header.Set("If-None-Match", u.etag)
+	}
+	client := u.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && u.body != nil:
+		return u.body, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GET %s - %s", u.URL, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	u.etag, u.body = resp.Header.Get("ETag"), body
+	return body, nil
+}
+
+func (u *URL) String() string { return u.URL }
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Git reads the config at Ref from the git working copy Path is in, fetching
+// first when the working copy has a remote so that refs like origin/main move.
+type Git struct {
+	Path string
+	Ref  string
+}
+
+func (g Git) Fetch() ([]byte, error) {
+	dir := filepath.Dir(g.Path)
+	remotes, err := g.git(dir, "remote")
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(remotes)) > 0 {
+		if _, err := g.git(dir, "fetch", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+	return g.git(dir, "show", g.Ref+":./"+filepath.Base(g.Path))
+}
+
+func (g Git) String() string { return g.Path + "@" + g.Ref }
+
+func (g Git) git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s - %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package parser_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/jaresty/concourse-tracker-bot/parser"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("NewSource", func() {
+	It("fetches HTTP(S) URLs over HTTP", func() {
+		Expect(NewSource("https://example.com/groups.yml", "")).To(Equal(&URL{URL: "https://example.com/groups.yml"}))
+		Expect(NewSource("http://example.com/groups.yml", "main")).To(Equal(&URL{URL: "http://example.com/groups.yml"}))
+	})
+
+	It("reads paths at a ref from git", func() {
+		Expect(NewSource("ci/groups.yml", "origin/main")).To(Equal(Git{Path: "ci/groups.yml", Ref: "origin/main"}))
+	})
+
+	It("reads other paths from disk", func() {
+		Expect(NewSource("groups.yml", "")).To(Equal(File("groups.yml")))
+	})
+})
+
+var _ = Describe("URL", func() {
+	var (
+		server *ghttp.Server
+		source *URL
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		source = &URL{URL: server.URL() + "/groups.yml"}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("fetches the config again only when its ETag changed", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/groups.yml"),
+				ghttp.RespondWith(http.StatusOK, "luna:\n- cf-deployment\n", http.Header{"ETag": {`"v1"`}}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+				ghttp.RespondWith(http.StatusNotModified, nil),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+				ghttp.RespondWith(http.StatusOK, "sol:\n- cf-acceptance\n", http.Header{"ETag": {`"v2"`}}),
+			),
+		)
+
+		Expect(source.Fetch()).To(Equal([]byte("luna:\n- cf-deployment\n")))
+		Expect(source.Fetch()).To(Equal([]byte("luna:\n- cf-deployment\n")))
+		Expect(source.Fetch()).To(Equal([]byte("sol:\n- cf-acceptance\n")))
+	})
+
+	It("fails on an error status", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, "not found"))
+
+		_, err := source.Fetch()
+		Expect(err).To(MatchError("GET " + server.URL() + "/groups.yml - 404 Not Found"))
+	})
+})
+
+var _ = Describe("Git", func() {
+	var (
+		dir string
+		git func(args ...string)
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "groups")
+		Expect(err).NotTo(HaveOccurred())
+		git = func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			out, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+		}
+
+		git("init", "--quiet")
+		Expect(os.Mkdir(filepath.Join(dir, "ci"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "ci", "groups.yml"), []byte("luna:\n- cf-deployment\n"), 0644)).To(Succeed())
+		git("add", ".")
+		git("commit", "--quiet", "-m", "groups")
+		git("tag", "v1")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads the file at the ref rather than the working copy", func() {
+		path := filepath.Join(dir, "ci", "groups.yml")
+		Expect(ioutil.WriteFile(path, []byte("sol:\n- cf-acceptance\n"), 0644)).To(Succeed())
+
+		Expect(Git{Path: path, Ref: "v1"}.Fetch()).To(Equal([]byte("luna:\n- cf-deployment\n")))
+	})
+
+	It("fetches remote refs first", func() {
+		clone, err := ioutil.TempDir("", "groups-clone")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(clone)
+		out, err := exec.Command("git", "clone", "--quiet", dir, clone).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "ci", "groups.yml"), []byte("sol:\n- cf-acceptance\n"), 0644)).To(Succeed())
+		git("commit", "--quiet", "-am", "more groups")
+		git("branch", "-f", "config")
+
+		Expect(Git{Path: filepath.Join(clone, "ci", "groups.yml"), Ref: "origin/config"}.Fetch()).To(Equal([]byte("sol:\n- cf-acceptance\n")))
+	})
+
+	It("fails when the file is not at the ref", func() {
+		_, err := Git{Path: filepath.Join(dir, "ci", "missing.yml"), Ref: "v1"}.Fetch()
+		Expect(err).To(MatchError(ContainSubstring("git show")))
+	})
+})
@@ -0,0 +1,248 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+const Ungrouped = "ungrouped"
+
+// TopJobs is the number of most frequently failing jobs listed per row.
+const TopJobs = 3
+
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+func ParsePeriod(name string) (Period, error) {
+	switch Period(name) {
+	case Day, Week, Month:
+		return Period(name), nil
+	}
+	return "", fmt.Errorf("unknown period %q, expected day, week or month", name)
+}
+
+// Start returns the start of the period t falls in, in UTC. Weeks start on
+// Monday.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case Week:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// Breakage is a run of failures of the jobs filed under one title, from its
+// first red build to the first green build after which all of them passed.
+// Recovered is zero while the title is still red.
+type Breakage struct {
+	Title     string
+	Group     string
+	Started   time.Time
+	Recovered time.Time
+	Failures  map[string]int
+}
+
+// Breakages pairs the failures of each title with its recovery. A build that
+// was recorded twice, e.g. across a restart of the bot, is counted once.
+func Breakages(events []status_groomer.BreakageEvent) []Breakage {
+	open := map[string]*Breakage{}
+	seen := map[string]bool{}
+	breakages := []Breakage{}
+	for _, event := range events {
+		switch event.Type {
+		case status_groomer.BuildFailed:
+			build := fmt.Sprintf("%s/%s/%d", event.Pipeline, event.Job, event.BuildID)
+			if seen[build] {
+				continue
+			}
+			seen[build] = true
+
+			b, ok := open[event.Title]
+			if !ok {
+				b = &Breakage{Title: event.Title, Group: event.Group, Started: event.Time, Failures: map[string]int{}}
+				if b.Group == "" {
+					b.Group = Ungrouped
+				}
+				open[event.Title] = b
+			}
+			b.Failures[event.Pipeline+"/"+event.Job]++
+		case status_groomer.TitleRecovered:
+			if b, ok := open[event.Title]; ok {
+				b.Recovered = event.Time
+				breakages = append(breakages, *b)
+				delete(open, event.Title)
+			}
+		}
+	}
+	for _, b := range open {
+		breakages = append(breakages, *b)
+	}
+	sort.Slice(breakages, func(i, j int) bool { return breakages[i].Started.Before(breakages[j].Started) })
+	return breakages
+}
+
+type JobCount struct {
+	Job      string
+	Failures int
+}
+
+// Row sums up the breakages of a group that started in a period. MTTR is the
+// mean time to recovery of the breakages that recovered; RedTime counts the
+// breakages still red up to now.
+type Row struct {
+	Group     string
+	Period    time.Time
+	Breakages int
+	Recovered int
+	MTTR      time.Duration
+	RedTime   time.Duration
+	TopJobs   []JobCount
+}
+
+// New reports the breakages that started at or after since by group and
+// period.
+func New(events []status_groomer.BreakageEvent, period Period, since, now time.Time) []Row {
+	type key struct {
+		group  string
+		period time.Time
+	}
+	rows := map[key]*Row{}
+	failures := map[key]map[string]int{}
+	recoveryTime := map[key]time.Duration{}
+	for _, b := range Breakages(events) {
+		if b.Started.Before(since) {
+			continue
+		}
+		k := key{b.Group, period.Start(b.Started)}
+		row, ok := rows[k]
+		if !ok {
+			row = &Row{Group: b.Group, Period: k.period}
+			rows[k] = row
+			failures[k] = map[string]int{}
+		}
+
+		row.Breakages++
+		end := now
+		if !b.Recovered.IsZero() {
+			end = b.Recovered
+			row.Recovered++
+			recoveryTime[k] += b.Recovered.Sub(b.Started)
+		}
+		row.RedTime += end.Sub(b.Started)
+		for job, n := range b.Failures {
+			failures[k][job] += n
+		}
+	}
+
+	report := []Row{}
+	for k, row := range rows {
+		if row.Recovered > 0 {
+			row.MTTR = recoveryTime[k] / time.Duration(row.Recovered)
+		}
+		row.TopJobs = topJobs(failures[k])
+		report = append(report, *row)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if !report[i].Period.Equal(report[j].Period) {
+			return report[i].Period.Before(report[j].Period)
+		}
+		return report[i].Group < report[j].Group
+	})
+	return report
+}
+
+func topJobs(failures map[string]int) []JobCount {
+	jobs := []JobCount{}
+	for job, n := range failures {
+		jobs = append(jobs, JobCount{Job: job, Failures: n})
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Failures != jobs[j].Failures {
+			return jobs[i].Failures > jobs[j].Failures
+		}
+		return jobs[i].Job < jobs[j].Job
+	})
+	if len(jobs) > TopJobs {
+		jobs = jobs[:TopJobs]
+	}
+	return jobs
+}
+
+func WriteCSV(w io.Writer, rows []Row) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"period", "group", "breakages", "recovered", "mttr_hours", "red_hours", "top_jobs"})
+	for _, row := range rows {
+		out.Write([]string{
+			row.Period.Format("2006-01-02"),
+			row.Group,
+			strconv.Itoa(row.Breakages),
+			strconv.Itoa(row.Recovered),
+			hours(row.MTTR),
+			hours(row.RedTime),
+			formatJobs(row.TopJobs),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func WriteMarkdown(w io.Writer, rows []Row) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No breakages.")
+		return err
+	}
+
+	fmt.Fprintln(w, "| Period | Group | Breakages | MTTR | Time red | Most failing jobs |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- | --- |")
+	for _, row := range rows {
+		mttr := "-"
+		if row.Recovered > 0 {
+			mttr = duration(row.MTTR)
+		}
+		jobs := formatJobs(row.TopJobs)
+		if jobs == "" {
+			jobs = "-"
+		}
+		if _, err := fmt.Fprintf(w, "| %s | %s | %d | %s | %s | %s |\n",
+			row.Period.Format("2006-01-02"), row.Group, row.Breakages, mttr, duration(row.RedTime), jobs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatJobs(jobs []JobCount) string {
+	parts := make([]string, len(jobs))
+	for i, job := range jobs {
+		parts[i] = fmt.Sprintf("%s (%d)", job.Job, job.Failures)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
+
+func duration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,110 @@
+package report_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/report"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	var (
+		monday time.Time
+		now    time.Time
+		events []status_groomer.BreakageEvent
+	)
+
+	failed := func(at time.Time, title, group, job string, build int) status_groomer.BreakageEvent {
+		return status_groomer.BreakageEvent{Type: status_groomer.BuildFailed, Time: at, Title: title, Group: group, Pipeline: "cf", Job: job, BuildID: build}
+	}
+	recovered := func(at time.Time, title string) status_groomer.BreakageEvent {
+		return status_groomer.BreakageEvent{Type: status_groomer.TitleRecovered, Time: at, Title: title}
+	}
+
+	BeforeEach(func() {
+		monday = time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+		now = monday.AddDate(0, 0, 10)
+		events = []status_groomer.BreakageEvent{
+			failed(monday.Add(1*time.Hour), "luna has failed", "luna", "deploy", 1),
+			failed(monday.Add(2*time.Hour), "luna has failed", "luna", "smoke", 7),
+			failed(monday.Add(2*time.Hour), "luna has failed", "luna", "deploy", 2),
+			failed(monday.Add(2*time.Hour), "luna has failed", "luna", "deploy", 2),
+			recovered(monday.Add(3*time.Hour), "luna has failed"),
+			failed(monday.Add(24*time.Hour), "luna has failed", "luna", "smoke", 8),
+			recovered(monday.Add(25*time.Hour), "luna has failed"),
+			failed(monday.Add(48*time.Hour), "cf/unit has failed", "", "unit", 3),
+			failed(monday.AddDate(0, 0, 8), "luna has failed", "luna", "deploy", 9),
+		}
+	})
+
+	It("pairs failures with recoveries", func() {
+		breakages := report.Breakages(events)
+		Expect(breakages).To(HaveLen(4))
+		Expect(breakages[0]).To(Equal(report.Breakage{
+			Title:     "luna has failed",
+			Group:     "luna",
+			Started:   monday.Add(time.Hour),
+			Recovered: monday.Add(3 * time.Hour),
+			Failures:  map[string]int{"cf/deploy": 2, "cf/smoke": 1},
+		}))
+		Expect(breakages[2].Group).To(Equal(report.Ungrouped))
+		Expect(breakages[3].Recovered).To(BeZero())
+	})
+
+	It("sums up breakages by group and period", func() {
+		rows := report.New(events, report.Week, time.Time{}, now)
+		Expect(rows).To(Equal([]report.Row{
+			{
+				Group: "luna", Period: monday, Breakages: 2, Recovered: 2,
+				MTTR: 90 * time.Minute, RedTime: 3 * time.Hour,
+				TopJobs: []report.JobCount{{Job: "cf/deploy", Failures: 2}, {Job: "cf/smoke", Failures: 2}},
+			},
+			{
+				Group: report.Ungrouped, Period: monday, Breakages: 1,
+				RedTime: now.Sub(monday.Add(48 * time.Hour)),
+				TopJobs: []report.JobCount{{Job: "cf/unit", Failures: 1}},
+			},
+			{
+				Group: "luna", Period: monday.AddDate(0, 0, 7), Breakages: 1,
+				RedTime: 2 * 24 * time.Hour,
+				TopJobs: []report.JobCount{{Job: "cf/deploy", Failures: 1}},
+			},
+		}))
+	})
+
+	It("leaves out breakages before since", func() {
+		rows := report.New(events, report.Week, monday.AddDate(0, 0, 7), now)
+		Expect(rows).To(HaveLen(1))
+		Expect(rows[0].Period).To(Equal(monday.AddDate(0, 0, 7)))
+	})
+
+	It("starts weeks on Monday and months on the first", func() {
+		sunday := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+		Expect(report.Week.Start(sunday)).To(Equal(monday))
+		Expect(report.Month.Start(sunday)).To(Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(report.Day.Start(sunday)).To(Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("writes CSV", func() {
+		var out bytes.Buffer
+		Expect(report.WriteCSV(&out, report.New(events[:5], report.Week, time.Time{}, now))).To(Succeed())
+		Expect(out.String()).To(Equal("period,group,breakages,recovered,mttr_hours,red_hours,top_jobs\n" +
+			"2026-10-12,luna,1,1,2.00,2.00,cf/deploy (2); cf/smoke (1)\n"))
+	})
+
+	It("writes Markdown", func() {
+		var out bytes.Buffer
+		Expect(report.WriteMarkdown(&out, report.New(events, report.Week, monday.AddDate(0, 0, 7), now))).To(Succeed())
+		Expect(out.String()).To(Equal("| Period | Group | Breakages | MTTR | Time red | Most failing jobs |\n" +
+			"| --- | --- | --- | --- | --- | --- |\n" +
+			"| 2026-10-19 | luna | 1 | - | 48h00m | cf/deploy (1) |\n"))
+
+		out.Reset()
+		Expect(report.WriteMarkdown(&out, nil)).To(Succeed())
+		Expect(out.String()).To(Equal("No breakages.\n"))
+	})
+})
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/audit"
+	"github.com/jaresty/concourse-tracker-bot/history"
+	"github.com/jaresty/concourse-tracker-bot/report"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+func reportCommand(args []string) error {
+	var opts options
+	var periodName, sinceFlag, format, digestStory string
+	var digest bool
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	opts.register(flags)
+	flags.StringVar(&periodName, "period", "week", "period to sum breakages up by: day, week or month")
+	flags.StringVar(&sinceFlag, "since", "", "only report breakages that started after a date (2006-01-02) or a duration ago (672h)")
+	flags.StringVar(&format, "format", "csv", "output format: csv or markdown")
+	flags.BoolVar(&digest, "digest", false, "also file the report as a story in the issue tracker")
+	flags.StringVar(&digestStory, "digest-story", "", "also post the report as a comment on the story with this ID")
+	flags.Parse(args)
+
+	if opts.historyFile == "" {
+		return errors.New("-history-file is required")
+	}
+	period, err := report.ParsePeriod(periodName)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	since, err := parseSince(sinceFlag, now)
+	if err != nil {
+		return err
+	}
+
+	events, err := (&history.Log{Path: opts.historyFile}).Events()
+	if err != nil {
+		return err
+	}
+	rows := report.New(events, period, since, now)
+
+	switch format {
+	case "csv":
+		err = report.WriteCSV(os.Stdout, rows)
+	case "markdown":
+		err = report.WriteMarkdown(os.Stdout, rows)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil || (!digest && digestStory == "") {
+		return err
+	}
+
+	var text bytes.Buffer
+	if since.IsZero() {
+		fmt.Fprintf(&text, "Build health by %s:\n\n", period)
+	} else {
+		fmt.Fprintf(&text, "Build health by %s since %s:\n\n", period, since.Format("2006-01-02"))
+	}
+	report.WriteMarkdown(&text, rows)
+
+	backend, err := issueBackend(opts.backendName, opts.httpConfig, opts.jiraConfigFile, opts.httpClient)
+	if err != nil {
+		return err
+	}
+	comment := status_groomer.Comment{Text: text.String()}
+	if digestStory != "" {
+		err := backend.AddComment(digestStory, comment)
+		return auditDigest(opts.auditLog, status_groomer.AuditEntry{Action: "comment", StoryID: digestStory, Payload: comment}, err)
+	}
+	reporter, ok := backend.(status_groomer.ReportFiler)
+	if !ok {
+		return errors.New("the issue backend cannot file reports, use -digest-story instead")
+	}
+	title := fmt.Sprintf("Build health report for %s", now.Format("2006-01-02"))
+	issue, err := reporter.FileReport(title, comment)
+	return auditDigest(opts.auditLog, status_groomer.AuditEntry{Action: "report", StoryID: issue.ID, StoryURL: issue.URL, Title: title, Payload: comment}, err)
+}
+
+// auditDigest records the digest in the audit log, if one is set, like every
+// other change the bot makes in the issue tracker. It returns err, or the
+// error recording the entry.
+func auditDigest(path string, entry status_groomer.AuditEntry, err error) error {
+	if path == "" {
+		return err
+	}
+	entry.Time = time.Now()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if recordErr := (&audit.Log{Path: path}).Record(entry); err == nil {
+		err = recordErr
+	}
+	return err
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+	since, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("-since must be a date like 2006-01-02 or a duration like 672h, got %q", value)
+	}
+	return since, nil
+}
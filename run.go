@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/concourse"
+	"github.com/jaresty/concourse-tracker-bot/github"
+	"github.com/jaresty/concourse-tracker-bot/jira"
+	"github.com/jaresty/concourse-tracker-bot/metrics"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+	"github.com/jaresty/concourse-tracker-bot/server"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+)
+
+func runCommand(args []string) error {
+	var opts options
+	var listen string
+	var pollInterval time.Duration
+	var streamBuilds bool
+	var readyMaxAge time.Duration
+	var reloadInterval time.Duration
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	opts.register(flags)
+	flags.StringVar(&listen, "listen", os.Getenv("LISTEN_ADDR"), "address to serve the dashboard, pushed builds, Tracker webhooks, metrics and health checks on, e.g. :8080; polling only when empty")
+	flags.DurationVar(&pollInterval, "poll-interval", 5*time.Minute, "time between full polls of every job; raise it when pipelines push their builds")
+	flags.BoolVar(&streamBuilds, "stream-builds", false, "follow the event streams of running builds and file failures as soon as they finish")
+	flags.DurationVar(&readyMaxAge, "ready-max-age", 0, "how old the last successful poll may be before /readyz fails (default three poll intervals)")
+	flags.DurationVar(&reloadInterval, "group-config-reload-interval", 10*time.Second, "how often to check a local group config file for changes; a remote one is fetched after every poll, and either is reloaded on SIGHUP")
+	flags.Parse(args)
+
+	log, err := opts.logger()
+	if err != nil {
+		return err
+	}
+
+	// the services the bot's requests go to are named by instrument once the
+	// groomer is built
+	var m *metrics.Metrics
+	services := map[string]string{}
+	if listen != "" {
+		m = metrics.New()
+		opts.httpClient = &http.Client{
+			Transport: metrics.Transport{Metrics: m, Services: services},
+			Timeout:   30 * time.Second,
+		}
+	}
+	groomer, webhooks, err := opts.groomer(log)
+	if err != nil {
+		return err
+	}
+	if opts.dryRun {
+		return opts.runDry(groomer)
+	}
+	groomer.Interval = pollInterval
+	go flushEvery(webhooks, time.Minute)
+
+	if opts.groupConfig != nil {
+		// a local file is checked every interval, a remote one after every poll
+		opts.groupConfig.Apply = groomer.SetGroupingStrategy
+		if _, local := opts.groupConfig.Source.(parser.File); local {
+			opts.groupConfig.Interval = reloadInterval
+		} else {
+			groomer.Observers = append(groomer.Observers, opts.groupConfig)
+		}
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go opts.groupConfig.Run(nil, hup)
+	}
+
+	if listen != "" {
+		groomer.Notifiers = append(groomer.Notifiers, m)
+		groomer.Observers = append(groomer.Observers, m)
+		instrument(m, groomer, services)
+		if readyMaxAge == 0 {
+			readyMaxAge = 3 * pollInterval
+		}
+		health := server.NewHealth(readyMaxAge)
+		groomer.Observers = append(groomer.Observers, health)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Registry)
+		mux.HandleFunc("/healthz", health.Healthz)
+		mux.HandleFunc("/readyz", health.Readyz)
+		mux.Handle("/", server.Dashboard{Groomers: []server.StatusSource{groomer}, Log: log})
+		if token := os.Getenv("PUSH_TOKEN"); token != "" {
+			mux.Handle("/builds", &server.PushHandler{
+				Processor: groomer,
+				Team:      groomer.Team,
+				Token:     token,
+				Log:       log,
+			})
+		} else {
+			log.Info("not accepting pushed builds, PUSH_TOKEN is not set")
+		}
+		if opts.backendName == "" || opts.backendName == "tracker" {
+			if token := os.Getenv("TRACKER_WEBHOOK_TOKEN"); token != "" {
+				projectID, _ := strconv.Atoi(os.Getenv("TRACKER_PROJECT_ID"))
+				mux.Handle("/tracker", server.TrackerHandler{
+					Triager:   groomer,
+					ProjectID: projectID,
+					Token:     token,
+					Log:       log,
+				})
+			} else {
+				log.Info("not accepting Tracker webhooks, TRACKER_WEBHOOK_TOKEN is not set")
+			}
+		}
+		go func() {
+			err := http.ListenAndServe(listen, mux)
+			log.Error("stopped serving HTTP", "listen", listen, "error", err)
+			os.Exit(1)
+		}()
+	}
+
+	if streamBuilds {
+		watcher := &concourse.Watcher{
+			Host: groomer.Host,
+			Team: groomer.Team,
+			Log:  log,
+			Finished: func(build concourse.BuildSummary) {
+				ref := status_groomer.BuildRef{Team: build.TeamName, Pipeline: build.PipelineName, Job: build.JobName, BuildID: build.ID}
+				if err := groomer.ProcessBuild(ref); err != nil {
+					log.Error("failed to process streamed build", "pipeline", build.PipelineName, "job", build.JobName, "build_id", build.ID, "error", err)
+				}
+			},
+		}
+		if opts.httpClient != nil {
+			// build event streams stay open, so they are not timed out
+			watcher.HTTPClient = &http.Client{Transport: opts.httpClient.Transport}
+		}
+		go watcher.Run(nil)
+	}
+
+	return groomer.Run(-1)
+}
+
+// instrument names the Concourse and issue tracker hosts in services, the
+// metrics of every client but the Tracker one, and records the requests of
+// the Tracker client, which is built with a transport of its own.
+func instrument(m *metrics.Metrics, groomer *status_groomer.Groomer, services map[string]string) {
+	services[hostOf(groomer.Host)] = "concourse"
+	switch backend := groomer.Backend.(type) {
+	case status_groomer.TrackerBackend:
+		if client, ok := backend.Client.(tracker.Client); ok && client.HTTPClient != nil {
+			client.HTTPClient.Transport = metrics.Transport{
+				Base:     client.HTTPClient.Transport,
+				Metrics:  m,
+				Services: map[string]string{hostOf(client.TrackerAPI): "tracker"},
+			}
+		}
+	case github.Client:
+		services[hostOf(backend.GitHubAPI)] = "github"
+	case jira.Client:
+		services[hostOf(backend.URL)] = "jira"
+	}
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
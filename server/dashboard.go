@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type StatusSource interface {
+	Status() status_groomer.Status
+}
+
+// Dashboard is a read-only page of the latest build of every watched job,
+// with one section per groomer.
+type Dashboard struct {
+	Groomers []StatusSource
+	Log      status_groomer.Logger
+}
+
+func (d Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teams := []status_groomer.Status{}
+	for _, groomer := range d.Groomers {
+		teams = append(teams, groomer.Status())
+	}
+
+	var page bytes.Buffer
+	if err := dashboardTemplate.Execute(&page, dashboardPage{Teams: teams, Now: time.Now()}); err != nil {
+		d.Log.Error("failed to render the dashboard", "error", err)
+		http.Error(w, "failed to render the dashboard", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page.Bytes())
+}
+
+type dashboardPage struct {
+	Teams []status_groomer.Status
+	Now   time.Time
+}
+
+// Since formats how long ago t was, e.g. "3h12m".
+func (p dashboardPage) Since(t time.Time) string {
+	d := p.Now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "less than a minute"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd", int(d.Hours())/24)
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>concourse-tracker-bot</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.3em 1em 0.3em 0; border-bottom: 1px solid #ddd; }
+.failed { color: #c0392b; font-weight: bold; }
+.errored, .aborted { color: #d35400; }
+.succeeded { color: #27ae60; }
+.error { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>concourse-tracker-bot</h1>
+{{range .Teams}}
+<h2>Team {{.Team}}</h2>
+<p>
+{{if .LastPoll.IsZero}}Not polled yet.{{else}}Last poll {{$.Since .LastPoll}} ago at {{.LastPoll.Format "2006-01-02 15:04:05 MST"}}.{{end}}
+{{if .LastPollError}}<span class="error">The last poll failed: {{.LastPollError}}</span>{{end}}
+</p>
+<table>
+<tr><th>Job</th><th>Group</th><th>Status</th><th>Red for</th><th>Story</th></tr>
+{{range .Jobs}}
+<tr>
+<td><a href="{{.BuildURL}}">{{.Pipeline}}/{{.Job}}</a></td>
+<td>{{.Group}}</td>
+<td class="{{.Status}}">{{if .Status}}{{.Status}}{{else}}no builds{{end}}</td>
+<td>{{if not .RedSince.IsZero}}{{$.Since .RedSince}}{{end}}</td>
+<td>{{if .StoryURL}}<a href="{{.StoryURL}}">#{{.StoryID}}</a>{{else if .StoryID}}#{{.StoryID}}{{end}}</td>
+</tr>
+{{else}}
+<tr><td colspan="5">No jobs seen yet.</td></tr>
+{{end}}
+</table>
+<h3>Recent actions</h3>
+{{if .Actions}}
+<ul>
+{{range .Actions}}
+<li>{{.Time.Format "2006-01-02 15:04:05"}} {{.Type}}{{if .Title}} &ldquo;{{.Title}}&rdquo;{{end}}{{if .IssueURL}} <a href="{{.IssueURL}}">#{{.IssueID}}</a>{{else if .IssueID}} #{{.IssueID}}{{end}}{{if .Reason}}: {{.Reason}}{{end}}{{if .Error}}: {{.Error}}{{end}}</li>
+{{end}}
+</ul>
+{{else}}
+<p>None yet.</p>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
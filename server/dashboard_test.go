@@ -0,0 +1,86 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/server"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fixedStatus status_groomer.Status
+
+func (s fixedStatus) Status() status_groomer.Status {
+	return status_groomer.Status(s)
+}
+
+var _ = Describe("Dashboard", func() {
+	var (
+		status    status_groomer.Status
+		dashboard server.Dashboard
+	)
+
+	BeforeEach(func() {
+		now := time.Now()
+		status = status_groomer.Status{
+			Team:     "main",
+			LastPoll: now.Add(-2 * time.Minute),
+			Jobs: []status_groomer.JobStatus{
+				{
+					Pipeline: "cf-deployment", Job: "deploy", Group: "luna", Status: "failed",
+					BuildURL: "https://ci/builds/1", StoryID: "7", StoryURL: "https://issues/7",
+					RedSince: now.Add(-3*time.Hour - 12*time.Minute),
+				},
+				{Pipeline: "cf-deployment", Job: "<unit>", Status: "succeeded", BuildURL: "https://ci/builds/2"},
+			},
+			Actions: []status_groomer.Event{
+				{Type: status_groomer.StoryCreated, Time: now, Title: "luna has failed", IssueID: "7", IssueURL: "https://issues/7"},
+			},
+		}
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		dashboard = server.Dashboard{Groomers: []server.StatusSource{fixedStatus(status)}, Log: logging.NewRecorder()}
+		rec := httptest.NewRecorder()
+		dashboard.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	It("shows each job with its group, time red and story", func() {
+		rec := get("/")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+
+		body := rec.Body.String()
+		Expect(body).To(ContainSubstring("<h2>Team main</h2>"))
+		Expect(body).To(ContainSubstring("Last poll 2m ago"))
+		Expect(body).To(ContainSubstring(`<a href="https://ci/builds/1">cf-deployment/deploy</a>`))
+		Expect(body).To(ContainSubstring(`<td class="failed">failed</td>`))
+		Expect(body).To(ContainSubstring("<td>3h12m</td>"))
+		Expect(body).To(ContainSubstring(`<a href="https://issues/7">#7</a>`))
+		Expect(body).To(ContainSubstring("story_created &ldquo;luna has failed&rdquo;"))
+	})
+
+	It("escapes job names", func() {
+		Expect(get("/").Body.String()).To(ContainSubstring("cf-deployment/&lt;unit&gt;"))
+	})
+
+	It("shows failed polls and teams not polled yet", func() {
+		status.LastPollError = "concourse is down"
+		Expect(get("/").Body.String()).To(ContainSubstring("The last poll failed: concourse is down"))
+
+		status = status_groomer.Status{Team: "main"}
+		body := get("/").Body.String()
+		Expect(body).To(ContainSubstring("Not polled yet."))
+		Expect(body).To(ContainSubstring("No jobs seen yet."))
+	})
+
+	It("only serves the page itself", func() {
+		Expect(get("/favicon.ico").Code).To(Equal(http.StatusNotFound))
+	})
+})
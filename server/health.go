@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// Health watches the groomer's polls to answer platform health checks. The
+// bot is ready while its last successful poll is younger than MaxAge and the
+// issue tracker has not rejected its credentials.
+type Health struct {
+	MaxAge time.Duration
+
+	mu          sync.Mutex
+	started     time.Time
+	lastSuccess time.Time
+	rejected    error
+}
+
+func NewHealth(maxAge time.Duration) *Health {
+	return &Health{MaxAge: maxAge, started: time.Now()}
+}
+
+func (h *Health) CycleFinished(stats status_groomer.CycleStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case stats.CredentialsRejected:
+		h.rejected = stats.Err
+	case stats.Err == nil:
+		h.lastSuccess = stats.Started.Add(stats.Duration)
+		h.rejected = nil
+	}
+}
+
+// Ready returns why the bot is not ready, or nil when it is.
+func (h *Health) Ready() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.rejected != nil {
+		return fmt.Errorf("the issue tracker rejected the bot's credentials - %s", h.rejected)
+	}
+	if h.lastSuccess.IsZero() {
+		if time.Since(h.started) > h.MaxAge {
+			return fmt.Errorf("no poll has succeeded in the %s since the bot started", time.Since(h.started).Round(time.Second))
+		}
+		return nil
+	}
+	if age := time.Since(h.lastSuccess); age > h.MaxAge {
+		return fmt.Errorf("the last successful poll was %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+// Healthz answers as long as the process is serving requests.
+func (h *Health) Healthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+func (h *Health) Readyz(w http.ResponseWriter, r *http.Request) {
+	if err := h.Ready(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,67 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/server"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health", func() {
+	var health *server.Health
+
+	BeforeEach(func() {
+		health = server.NewHealth(time.Minute)
+	})
+
+	readyz := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		health.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+		return rec
+	}
+
+	It("is alive as long as it serves requests", func() {
+		rec := httptest.NewRecorder()
+		health.Healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("is ready while the bot starts up", func() {
+		Expect(readyz().Code).To(Equal(http.StatusOK))
+	})
+
+	It("is not ready when no poll succeeds after starting up", func() {
+		health = &server.Health{MaxAge: time.Minute}
+		rec := readyz()
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rec.Body.String()).To(ContainSubstring("no poll has succeeded"))
+	})
+
+	It("is ready after a recent successful poll", func() {
+		health.CycleFinished(status_groomer.CycleStats{Started: time.Now()})
+		health.CycleFinished(status_groomer.CycleStats{Started: time.Now(), Err: errors.New("concourse is down")})
+		Expect(readyz().Code).To(Equal(http.StatusOK))
+	})
+
+	It("is not ready when the last successful poll is too old", func() {
+		health.CycleFinished(status_groomer.CycleStats{Started: time.Now().Add(-2 * time.Minute)})
+		rec := readyz()
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rec.Body.String()).To(ContainSubstring("the last successful poll was 2m0s ago"))
+	})
+
+	It("is not ready when the issue tracker rejects the credentials", func() {
+		health.CycleFinished(status_groomer.CycleStats{Started: time.Now()})
+		health.CycleFinished(status_groomer.CycleStats{Started: time.Now(), Err: errors.New("401 Unauthorized"), CredentialsRejected: true})
+
+		rec := readyz()
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rec.Body.String()).To(ContainSubstring("rejected the bot's credentials - 401 Unauthorized"))
+	})
+})
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type BuildProcessor interface {
+	ProcessBuild(status_groomer.BuildRef) error
+}
+
+// DefaultMaxInFlight is the number of pushed builds processed at once when
+// PushHandler.MaxInFlight is not set.
+const DefaultMaxInFlight = 8
+
+// PushHandler accepts build reports from pipelines so failures are filed as
+// soon as they happen instead of on the next poll. Reports must carry Token;
+// without one every report is refused. Reports beyond MaxInFlight builds
+// being processed are turned away for the pipeline to retry.
+type PushHandler struct {
+	Processor   BuildProcessor
+	Team        string
+	Token       string
+	MaxInFlight int
+	Log         status_groomer.Logger
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+func (h *PushHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if h.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var ref status_groomer.BuildRef
+	if err := json.NewDecoder(r.Body).Decode(&ref); err != nil {
+		http.Error(w, "invalid build: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ref.Pipeline == "" || ref.Job == "" {
+		http.Error(w, "pipeline and job are required", http.StatusUnprocessableEntity)
+		return
+	}
+	if ref.Team == "" {
+		ref.Team = h.Team
+	}
+	if ref.Team != h.Team {
+		http.Error(w, "builds of team "+ref.Team+" are not watched", http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.once.Do(func() {
+		max := h.MaxInFlight
+		if max <= 0 {
+			max = DefaultMaxInFlight
+		}
+		h.slots = make(chan struct{}, max)
+	})
+	select {
+	case h.slots <- struct{}{}:
+	default:
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "too many builds in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	go func() {
+		defer func() { <-h.slots }()
+		if err := h.Processor.ProcessBuild(ref); err != nil {
+			h.Log.Error("failed to process pushed build", "pipeline", ref.Pipeline, "job", ref.Job, "build_id", ref.BuildID, "error", err)
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,106 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/server"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeProcessor struct {
+	sync.Mutex
+	refs    []status_groomer.BuildRef
+	release chan struct{}
+}
+
+func (p *fakeProcessor) ProcessBuild(ref status_groomer.BuildRef) error {
+	p.Lock()
+	p.refs = append(p.refs, ref)
+	p.Unlock()
+	if p.release != nil {
+		<-p.release
+	}
+	return nil
+}
+
+func (p *fakeProcessor) Refs() []status_groomer.BuildRef {
+	p.Lock()
+	defer p.Unlock()
+	return append([]status_groomer.BuildRef{}, p.refs...)
+}
+
+var _ = Describe("PushHandler", func() {
+	var (
+		processor *fakeProcessor
+		handler   *server.PushHandler
+	)
+
+	push := func(method, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/builds", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	BeforeEach(func() {
+		processor = &fakeProcessor{}
+		handler = &server.PushHandler{
+			Processor: processor,
+			Team:      "main",
+			Token:     "s3cr3t",
+			Log:       logging.NewRecorder(),
+		}
+	})
+
+	It("hands the reported build to the groomer", func() {
+		rec := push("POST", `{"pipeline":"cf-deployment","job":"fresh-deploy","build_id":42}`, "s3cr3t")
+
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+		Eventually(processor.Refs).Should(Equal([]status_groomer.BuildRef{
+			{Team: "main", Pipeline: "cf-deployment", Job: "fresh-deploy", BuildID: 42},
+		}))
+	})
+
+	It("rejects requests without the shared token", func() {
+		Expect(push("POST", `{"pipeline":"p","job":"j"}`, "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(push("POST", `{"pipeline":"p","job":"j"}`, "wrong").Code).To(Equal(http.StatusUnauthorized))
+		Consistently(processor.Refs).Should(BeEmpty())
+	})
+
+	It("refuses every request when no token is configured", func() {
+		handler.Token = ""
+		Expect(push("POST", `{"pipeline":"p","job":"j"}`, "").Code).To(Equal(http.StatusUnauthorized))
+		Consistently(processor.Refs).Should(BeEmpty())
+	})
+
+	It("turns reports away while too many builds are being processed", func() {
+		processor.release = make(chan struct{})
+		defer close(processor.release)
+		handler.MaxInFlight = 2
+
+		Expect(push("POST", `{"pipeline":"p","job":"j1"}`, "s3cr3t").Code).To(Equal(http.StatusAccepted))
+		Expect(push("POST", `{"pipeline":"p","job":"j2"}`, "s3cr3t").Code).To(Equal(http.StatusAccepted))
+		rec := push("POST", `{"pipeline":"p","job":"j3"}`, "s3cr3t")
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rec.Header().Get("Retry-After")).To(Equal("60"))
+		Eventually(processor.Refs).Should(HaveLen(2))
+	})
+
+	It("rejects malformed and incomplete reports", func() {
+		Expect(push("GET", "", "s3cr3t").Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(push("POST", `not json`, "s3cr3t").Code).To(Equal(http.StatusBadRequest))
+		Expect(push("POST", `{"pipeline":"p"}`, "s3cr3t").Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(push("POST", `{"team":"other","pipeline":"p","job":"j"}`, "s3cr3t").Code).To(Equal(http.StatusUnprocessableEntity))
+		Consistently(processor.Refs).Should(BeEmpty())
+	})
+})
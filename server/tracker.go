@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+)
+
+type Triager interface {
+	StoryAccepted(issueID string) error
+	StoryDeleted(issueID string) error
+	StoryRenamed(issueID string, name string) error
+}
+
+// TrackerHandler receives Tracker activity webhooks so the bot learns about
+// humans accepting, deleting or renaming its stories. Tracker cannot sign its
+// webhooks, so the token is passed in the webhook URL's query string; without
+// a Token every request is refused.
+type TrackerHandler struct {
+	Triager   Triager
+	ProjectID int
+	Token     string
+	Log       status_groomer.Logger
+}
+
+func (h TrackerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.Token == "" || subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(h.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var activity tracker.Activity
+	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
+		http.Error(w, "invalid activity: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.ProjectID != 0 && activity.Project.ID != h.ProjectID {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	for _, change := range activity.Changes {
+		if change.Kind != "story" {
+			continue
+		}
+
+		id := strconv.Itoa(change.ID)
+		var err error
+		switch {
+		case change.ChangeType == "delete":
+			err = h.Triager.StoryDeleted(id)
+		case change.NewValues.CurrentState == "accepted":
+			err = h.Triager.StoryAccepted(id)
+		case change.NewValues.Name != "" && change.NewValues.Name != change.OriginalValues.Name:
+			err = h.Triager.StoryRenamed(id, change.NewValues.Name)
+		}
+		if err != nil {
+			h.Log.Error("failed to handle Tracker activity", "kind", activity.Kind, "story_id", id, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
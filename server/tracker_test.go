@@ -0,0 +1,119 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	"github.com/jaresty/concourse-tracker-bot/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeTriager struct {
+	calls []string
+	err   error
+}
+
+func (t *fakeTriager) StoryAccepted(id string) error {
+	t.calls = append(t.calls, "accepted "+id)
+	return t.err
+}
+
+func (t *fakeTriager) StoryDeleted(id string) error {
+	t.calls = append(t.calls, "deleted "+id)
+	return t.err
+}
+
+func (t *fakeTriager) StoryRenamed(id string, name string) error {
+	t.calls = append(t.calls, "renamed "+id+" to "+name)
+	return t.err
+}
+
+var _ = Describe("TrackerHandler", func() {
+	var (
+		triager *fakeTriager
+		handler server.TrackerHandler
+	)
+
+	post := func(path, body string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return rec.Code
+	}
+
+	BeforeEach(func() {
+		triager = &fakeTriager{}
+		handler = server.TrackerHandler{
+			Triager:   triager,
+			ProjectID: 99,
+			Token:     "s3cr3t",
+			Log:       logging.NewRecorder(),
+		}
+	})
+
+	It("reports accepted stories", func() {
+		Expect(post("/tracker?token=s3cr3t", `{
+			"kind": "story_update_activity",
+			"highlight": "accepted",
+			"changes": [{"kind": "story", "change_type": "update", "id": 563,
+				"original_values": {"current_state": "delivered"},
+				"new_values": {"current_state": "accepted"}}],
+			"project": {"kind": "project", "id": 99}
+		}`)).To(Equal(http.StatusNoContent))
+
+		Expect(triager.calls).To(Equal([]string{"accepted 563"}))
+	})
+
+	It("reports deleted and renamed stories", func() {
+		Expect(post("/tracker?token=s3cr3t", `{
+			"kind": "story_delete_activity",
+			"changes": [{"kind": "story", "change_type": "delete", "id": 563}],
+			"project": {"id": 99}
+		}`)).To(Equal(http.StatusNoContent))
+		Expect(post("/tracker?token=s3cr3t", `{
+			"kind": "story_update_activity",
+			"changes": [
+				{"kind": "label", "change_type": "create", "id": 1},
+				{"kind": "story", "change_type": "update", "id": 564,
+					"original_values": {"name": "luna has failed"},
+					"new_values": {"name": "luna is flaky"}}],
+			"project": {"id": 99}
+		}`)).To(Equal(http.StatusNoContent))
+
+		Expect(triager.calls).To(Equal([]string{"deleted 563", "renamed 564 to luna is flaky"}))
+	})
+
+	It("ignores activity in other projects", func() {
+		Expect(post("/tracker?token=s3cr3t", `{
+			"changes": [{"kind": "story", "change_type": "delete", "id": 563}],
+			"project": {"id": 100}
+		}`)).To(Equal(http.StatusNoContent))
+		Expect(triager.calls).To(BeEmpty())
+	})
+
+	It("rejects requests without the token", func() {
+		Expect(post("/tracker", `{}`)).To(Equal(http.StatusUnauthorized))
+		Expect(post("/tracker?token=nope", `{}`)).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("refuses every request when no token is configured", func() {
+		handler.Token = ""
+		Expect(post("/tracker", `{
+			"changes": [{"kind": "story", "change_type": "delete", "id": 563}],
+			"project": {"id": 99}
+		}`)).To(Equal(http.StatusUnauthorized))
+		Expect(triager.calls).To(BeEmpty())
+	})
+
+	It("fails so that Tracker retries when the bot cannot act", func() {
+		triager.err = errors.New("tracker is down")
+		Expect(post("/tracker?token=s3cr3t", `{
+			"changes": [{"kind": "story", "change_type": "update", "id": 563, "new_values": {"current_state": "accepted"}}],
+			"project": {"id": 99}
+		}`)).To(Equal(http.StatusInternalServerError))
+	})
+})
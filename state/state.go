@@ -0,0 +1,62 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+// File keeps the groomer's state in a JSON file, replaced as a whole on every
+// save so that a crash never leaves half of it behind.
+type File struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Load reads the state. A missing file is an empty state.
+func (f *File) Load() (status_groomer.State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var state status_groomer.State
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return status_groomer.State{}, err
+	}
+	return state, nil
+}
+
+func (f *File) Save(state status_groomer.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
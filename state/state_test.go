@@ -0,0 +1,56 @@
+package state_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jaresty/concourse-tracker-bot/state"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File", func() {
+	var (
+		dir  string
+		file *state.File
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "state")
+		Expect(err).NotTo(HaveOccurred())
+		file = &state.File{Path: filepath.Join(dir, "state.json")}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads a missing file as an empty state", func() {
+		Expect(file.Load()).To(Equal(status_groomer.State{}))
+	})
+
+	It("reads back what it saved", func() {
+		saved := status_groomer.State{
+			Escalated: map[string]map[string]bool{"groupa has failed": {"after=24h0m0s label=escalated move_to_top=false issue_type= notify=": true}},
+			Labeled:   map[string]bool{"7": true},
+			Ignored:   map[string]string{"groupb has failed": "https://ci/builds/3"},
+		}
+		Expect(file.Save(saved)).To(Succeed())
+		Expect(file.Load()).To(Equal(saved))
+
+		entries, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("fails on a corrupt file", func() {
+		Expect(ioutil.WriteFile(file.Path, []byte("{"), 0644)).To(Succeed())
+
+		_, err := file.Load()
+		Expect(err).To(HaveOccurred())
+	})
+})
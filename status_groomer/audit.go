@@ -0,0 +1,53 @@
+package status_groomer
+
+import "time"
+
+// AuditEntry records a change the groomer made in the issue tracker and the
+// build and group rule that led to it. Action uses the names of the dry run
+// plan.
+type AuditEntry struct {
+	Time     time.Time   `json:"time"`
+	Action   string      `json:"action"`
+	StoryID  string      `json:"story_id,omitempty"`
+	StoryURL string      `json:"story_url,omitempty"`
+	Title    string      `json:"title,omitempty"`
+	Group    string      `json:"group,omitempty"`
+	Rule     string      `json:"rule,omitempty"`
+	Pipeline string      `json:"pipeline,omitempty"`
+	Job      string      `json:"job,omitempty"`
+	BuildID  int         `json:"build_id,omitempty"`
+	BuildURL string      `json:"build_url,omitempty"`
+	Payload  interface{} `json:"payload,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type Auditor interface {
+	Record(AuditEntry) error
+}
+
+// audit records entry with the build of job. The group rule is filled in when
+// the story is the job's own rather than a shared one such as the storm or
+// flaky tests story.
+func (g *Groomer) audit(entry AuditEntry, job Job, err error) {
+	if g.Audit == nil {
+		return
+	}
+
+	entry.Time = time.Now()
+	if build := job.FinishedBuild; build.PipelineName != "" {
+		entry.Pipeline = build.PipelineName
+		entry.Job = build.JobName
+		entry.BuildID = build.ID
+		entry.BuildURL = g.buildURL(job)
+		if entry.Title == "" || entry.Title == g.storyName(job) {
+			entry.Rule, entry.Group = g.groupRule(job)
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if err := g.Audit.Record(entry); err != nil {
+		g.Log.Error("failed to record audit entry", "action", entry.Action, "story_id", entry.StoryID, "error", err)
+	}
+}
@@ -0,0 +1,63 @@
+package status_groomer
+
+import "strings"
+
+type Issue struct {
+	ID    string
+	Title string
+	URL   string
+}
+
+// Comment is rendered by each backend in its own markup. Link is the build
+// URL and is what the groomer looks for to avoid commenting twice.
+type Comment struct {
+	Text string
+	Link string
+}
+
+// hasLink reports whether text holds link as a whole URL rather than as the
+// start of a longer one, such as builds/15 within builds/150. Closing markup
+// like the brackets around a Jira link may follow it.
+func hasLink(text string, link string) bool {
+	for i := 0; ; {
+		found := strings.Index(text[i:], link)
+		if found < 0 {
+			return false
+		}
+		end := i + found + len(link)
+		if end == len(text) || !strings.ContainsRune(urlChars, rune(text[end])) {
+			return true
+		}
+		i += found + 1
+	}
+}
+
+// urlChars are the characters that can carry a URL on past a link.
+const urlChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~/?#%&=+:@!$*,;'"
+
+type NewIssue struct {
+	Title   string
+	Group   string
+	Comment Comment
+}
+
+type IssueBackend interface {
+	FindOpenIssue(title string) (*Issue, error)
+	CreateIssue(NewIssue) (Issue, error)
+	Comments(issueID string) ([]string, error)
+	AddComment(issueID string, comment Comment) error
+	ResolveIssue(issueID string) error
+	ReopenIssue(issueID string) error
+}
+
+// ReportFiler is implemented by backends that can file an issue that is not a
+// broken build, such as the build health report, without the broken build
+// label or a place at the top of the backlog.
+type ReportFiler interface {
+	FileReport(title string, comment Comment) (Issue, error)
+}
+
+// Labeler is implemented by backends that can label an existing issue.
+type Labeler interface {
+	AddLabel(issueID string, label string) error
+}
@@ -0,0 +1,91 @@
+package status_groomer
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	BuildFailed    = "failed"
+	TitleRecovered = "recovered"
+)
+
+// BreakageEvent is a line of the failure and recovery history: a failed build
+// of a job filed under Title, or the first green build once every job of the
+// title passes again.
+type BreakageEvent struct {
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	Title    string    `json:"title"`
+	Group    string    `json:"group,omitempty"`
+	Pipeline string    `json:"pipeline,omitempty"`
+	Job      string    `json:"job,omitempty"`
+	BuildID  int       `json:"build_id,omitempty"`
+}
+
+// BreakageHistory persists failures and recoveries. Broken returns the titles
+// whose last recorded event is a failure, so a restarted groomer still records
+// their recovery.
+type BreakageHistory interface {
+	Record(BreakageEvent) error
+	Broken() (map[string]bool, error)
+}
+
+// recordBreakages records builds that failed since the last cycle and titles
+// that recovered.
+func (g *Groomer) recordBreakages(jobs []Job, failing map[string]bool) error {
+	if g.Breakages == nil {
+		return nil
+	}
+	if g.broken == nil {
+		broken, err := g.Breakages.Broken()
+		if err != nil {
+			return err
+		}
+		g.broken = broken
+		g.lastFailed = map[string]int{}
+	}
+
+	recovered := map[string]BreakageEvent{}
+	for _, job := range jobs {
+		build := job.FinishedBuild
+		title := g.storyName(job)
+		key := build.PipelineName + "/" + build.JobName
+		last, recorded := g.lastFailed[key]
+		switch {
+		case build.Status == "failed" && (!recorded || last != build.ID):
+			g.lastFailed[key] = build.ID
+			g.broken[title] = true
+			g.recordBreakage(BreakageEvent{Type: BuildFailed, Time: finished(build), Title: title, Group: g.groupName(job), Pipeline: build.PipelineName, Job: build.JobName, BuildID: build.ID})
+		case build.Status == "succeeded" && g.broken[title] && !failing[title]:
+			// the title recovered with the last of its jobs to go green
+			if at := finished(build); at.After(recovered[title].Time) {
+				recovered[title] = BreakageEvent{Type: TitleRecovered, Time: at, Title: title, Group: g.groupName(job)}
+			}
+		}
+	}
+
+	titles := []string{}
+	for title := range recovered {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	for _, title := range titles {
+		delete(g.broken, title)
+		g.recordBreakage(recovered[title])
+	}
+	return nil
+}
+
+func (g *Groomer) recordBreakage(event BreakageEvent) {
+	if err := g.Breakages.Record(event); err != nil {
+		g.log.Error("failed to record breakage history", "type", event.Type, "title", event.Title, "error", err)
+	}
+}
+
+func finished(build Build) time.Time {
+	if build.EndTime != 0 {
+		return time.Unix(build.EndTime, 0)
+	}
+	return time.Now()
+}
@@ -0,0 +1,156 @@
+package status_groomer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type PlannedAction struct {
+	Action  string `json:"action"`
+	IssueID string `json:"issue_id,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Link    string `json:"link,omitempty"`
+}
+
+type Plan struct {
+	Actions []PlannedAction `json:"actions"`
+}
+
+// DryRunBackend reads from the wrapped backend but records every change in
+// Plan instead of making it.
+type DryRunBackend struct {
+	Backend IssueBackend
+	Plan    *Plan
+
+	titles   map[string]string
+	comments map[string][]string
+}
+
+func NewDryRunBackend(backend IssueBackend) *DryRunBackend {
+	return &DryRunBackend{
+		Backend:  backend,
+		Plan:     &Plan{Actions: []PlannedAction{}},
+		titles:   map[string]string{},
+		comments: map[string][]string{},
+	}
+}
+
+func (d *DryRunBackend) plan(action PlannedAction) {
+	if action.Title == "" {
+		action.Title = d.titles[action.IssueID]
+	}
+	d.Plan.Actions = append(d.Plan.Actions, action)
+}
+
+func (d *DryRunBackend) FindOpenIssue(title string) (*Issue, error) {
+	for id, planned := range d.titles {
+		if planned == title && strings.HasPrefix(id, "planned-") {
+			return &Issue{ID: id, Title: title}, nil
+		}
+	}
+
+	issue, err := d.Backend.FindOpenIssue(title)
+	if issue != nil {
+		d.titles[issue.ID] = issue.Title
+	}
+	return issue, err
+}
+
+func (d *DryRunBackend) CreateIssue(input NewIssue) (Issue, error) {
+	id := fmt.Sprintf("planned-%d", len(d.Plan.Actions)+1)
+	d.titles[id] = input.Title
+	d.comments[id] = []string{input.Comment.Text + " " + input.Comment.Link}
+	d.plan(PlannedAction{Action: "create", Title: input.Title, Group: input.Group, Text: input.Comment.Text, Link: input.Comment.Link})
+	return Issue{ID: id, Title: input.Title}, nil
+}
+
+func (d *DryRunBackend) Comments(issueID string) ([]string, error) {
+	if strings.HasPrefix(issueID, "planned-") {
+		return d.comments[issueID], nil
+	}
+	comments, err := d.Backend.Comments(issueID)
+	return append(comments, d.comments[issueID]...), err
+}
+
+func (d *DryRunBackend) AddComment(issueID string, comment Comment) error {
+	d.comments[issueID] = append(d.comments[issueID], comment.Text+" "+comment.Link)
+	d.plan(PlannedAction{Action: "comment", IssueID: issueID, Text: comment.Text, Link: comment.Link})
+	return nil
+}
+
+func (d *DryRunBackend) ResolveIssue(issueID string) error {
+	d.plan(PlannedAction{Action: "resolve", IssueID: issueID})
+	return nil
+}
+
+func (d *DryRunBackend) ReopenIssue(issueID string) error {
+	d.plan(PlannedAction{Action: "reopen", IssueID: issueID})
+	return nil
+}
+
+func (d *DryRunBackend) AddLabel(issueID string, label string) error {
+	d.plan(PlannedAction{Action: "label", IssueID: issueID, Text: label})
+	return nil
+}
+
+func (d *DryRunBackend) MoveToTop(issueID string) error {
+	d.plan(PlannedAction{Action: "move to top", IssueID: issueID})
+	return nil
+}
+
+func (d *DryRunBackend) SetType(issueID string, issueType string) error {
+	d.plan(PlannedAction{Action: "set type", IssueID: issueID, Text: issueType})
+	return nil
+}
+
+func (p Plan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+func (p Plan) WriteText(w io.Writer) error {
+	if len(p.Actions) == 0 {
+		_, err := fmt.Fprintln(w, "nothing to do")
+		return err
+	}
+
+	for _, a := range p.Actions {
+		var line string
+		switch a.Action {
+		case "create":
+			line = fmt.Sprintf("create story %q", a.Title)
+			if a.Group != "" {
+				line += fmt.Sprintf(" for group %s", a.Group)
+			}
+		case "comment":
+			line = fmt.Sprintf("comment on %s", describe(a))
+		case "label", "set type":
+			line = fmt.Sprintf("%s %s as %s", a.Action, describe(a), a.Text)
+			a.Text = ""
+		default:
+			line = fmt.Sprintf("%s %s", a.Action, describe(a))
+		}
+		if details := strings.TrimSpace(a.Text + " " + a.Link); details != "" {
+			line += ": " + details
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describe(a PlannedAction) string {
+	if a.Title == "" {
+		return "story " + a.IssueID
+	}
+	if strings.HasPrefix(a.IssueID, "planned-") {
+		return fmt.Sprintf("new story %q", a.Title)
+	}
+	return fmt.Sprintf("story %s %q", a.IssueID, a.Title)
+}
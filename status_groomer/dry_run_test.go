@@ -0,0 +1,112 @@
+package status_groomer_test
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	. "github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("DryRunBackend", func() {
+	var (
+		mockServer  *ghttp.Server
+		mockBackend *fakes.FakeIssueBackend
+		dryRun      *DryRunBackend
+		groomer     *Groomer
+	)
+
+	BeforeEach(func() {
+		mockServer = ghttp.NewServer()
+		mockBackend = new(fakes.FakeIssueBackend)
+		dryRun = NewDryRunBackend(mockBackend)
+
+		for path, status := range map[string]string{"/job1-groupa": "failed", "/job2-groupa": "failed", "/job3": "failed"} {
+			mockServer.RouteToHandler("GET", path, ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+				FinishedBuild: Build{JobName: path[1:], PipelineName: "fooPipeline", Status: status, URL: path + "/builds/1"},
+			}))
+		}
+		mockConcourseClient := new(fakes.FakeConcourseClient)
+		mockConcourseClient.GetJobURLsReturns([]string{
+			mockServer.URL() + "/job1-groupa",
+			mockServer.URL() + "/job2-groupa",
+			mockServer.URL() + "/job3",
+		}, nil)
+		mockBackend.FindOpenIssueStub = func(title string) (*Issue, error) {
+			if title == "fooPipeline/job3 has failed" {
+				return &Issue{ID: "5", Title: title}, nil
+			}
+			return nil, nil
+		}
+
+		groomer = &Groomer{
+			GroupingStrategy: map[string]string{"(fooPipeline-.*-groupa)": "groupa"},
+			Host:             "https://ci",
+			Team:             "main",
+			Backend:          dryRun,
+			Concourse:        mockConcourseClient,
+			Log:              logging.NewRecorder(),
+		}
+	})
+
+	AfterEach(func() {
+		mockServer.Close()
+	})
+
+	It("plans the changes of a cycle without making them", func() {
+		Expect(groomer.Cycle()).To(Succeed())
+
+		Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+		Expect(mockBackend.AddCommentCallCount()).To(Equal(0))
+		Expect(dryRun.Plan.Actions).To(Equal([]PlannedAction{
+			{Action: "create", Title: "groupa has failed", Group: "groupa", Link: "https://ci//job1-groupa/builds/1"},
+			{Action: "comment", IssueID: "planned-1", Title: "groupa has failed", Link: "https://ci//job2-groupa/builds/1"},
+			{Action: "comment", IssueID: "5", Title: "fooPipeline/job3 has failed", Link: "https://ci//job3/builds/1"},
+		}))
+	})
+
+	It("plans to resolve the stories of groups that have recovered", func() {
+		mockServer.RouteToHandler("GET", "/job3", ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+			FinishedBuild: Build{JobName: "job3", PipelineName: "fooPipeline", Status: "succeeded", URL: "/job3/builds/2"},
+		}))
+		groomer.ResolveRecovered = true
+		Expect(groomer.Cycle()).To(Succeed())
+
+		Expect(mockBackend.ResolveIssueCallCount()).To(Equal(0))
+		Expect(dryRun.Plan.Actions).To(ContainElement(PlannedAction{Action: "resolve", IssueID: "5", Title: "fooPipeline/job3 has failed"}))
+	})
+
+	It("prints the plan for humans", func() {
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(dryRun.ResolveIssue("5")).To(Succeed())
+		Expect(dryRun.AddLabel("5", "flaky")).To(Succeed())
+
+		out := &bytes.Buffer{}
+		Expect(dryRun.Plan.WriteText(out)).To(Succeed())
+		Expect(out.String()).To(Equal(`create story "groupa has failed" for group groupa: https://ci//job1-groupa/builds/1
+comment on new story "groupa has failed": https://ci//job2-groupa/builds/1
+comment on story 5 "fooPipeline/job3 has failed": https://ci//job3/builds/1
+resolve story 5 "fooPipeline/job3 has failed"
+label story 5 "fooPipeline/job3 has failed" as flaky
+`))
+	})
+
+	It("prints the plan as JSON", func() {
+		Expect(dryRun.ResolveIssue("5")).To(Succeed())
+
+		out := &bytes.Buffer{}
+		Expect(dryRun.Plan.WriteJSON(out)).To(Succeed())
+		Expect(out.String()).To(MatchJSON(`{"actions": [{"action": "resolve", "issue_id": "5"}]}`))
+	})
+
+	It("says when there is nothing to do", func() {
+		out := &bytes.Buffer{}
+		Expect(dryRun.Plan.WriteText(out)).To(Succeed())
+		Expect(out.String()).To(Equal("nothing to do\n"))
+	})
+})
@@ -0,0 +1,165 @@
+package status_groomer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Prioritizer is implemented by backends that can move an issue to the top
+// of the team's backlog.
+type Prioritizer interface {
+	MoveToTop(issueID string) error
+}
+
+// Retyper is implemented by backends that can change the type of an issue,
+// e.g. from a chore to a bug.
+type Retyper interface {
+	SetType(issueID string, issueType string) error
+}
+
+// Duration reads durations such as "24h" from YAML.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+type EscalationRule struct {
+	After     Duration `yaml:"after"`
+	Label     string   `yaml:"label"`
+	MoveToTop bool     `yaml:"move_to_top"`
+	IssueType string   `yaml:"issue_type"`
+	Notify    []string `yaml:"notify"`
+}
+
+// key identifies the rule in the groomer's state by what it does rather than
+// where it sits in the config, so reordering rules does not re-apply them.
+func (r EscalationRule) key() string {
+	return fmt.Sprintf("after=%s label=%s move_to_top=%t issue_type=%s notify=%s",
+		time.Duration(r.After), r.Label, r.MoveToTop, r.IssueType, strings.Join(r.Notify, ","))
+}
+
+// Escalation lists the rules applied to stories that stay red. Groups without
+// rules of their own use the default rules.
+type Escalation struct {
+	Default []EscalationRule            `yaml:"default"`
+	Groups  map[string][]EscalationRule `yaml:"groups"`
+	// Window is the number of builds looked back through for the start of a
+	// failure.
+	Window int `yaml:"window"`
+}
+
+func (e Escalation) rules(group string) []EscalationRule {
+	if rules, ok := e.Groups[group]; ok {
+		return rules
+	}
+	return e.Default
+}
+
+// failingSince finds when the job's current run of failures started from its
+// build history when escalating, falling back to the failed build itself.
+func (g *Groomer) failingSince(job Job) time.Time {
+	since := time.Now()
+	if job.FinishedBuild.StartTime != 0 {
+		since = time.Unix(job.FinishedBuild.StartTime, 0)
+	}
+	if g.History == nil || (len(g.Escalation.Default) == 0 && len(g.Escalation.Groups) == 0) {
+		return since
+	}
+
+	window := g.Escalation.Window
+	if window == 0 {
+		window = 100
+	}
+	builds, err := g.History.JobBuilds(g.Host, g.Team, job.FinishedBuild.PipelineName, job.FinishedBuild.JobName, window)
+	if err != nil {
+		g.jobLog(job).Warn("could not look up when the job started failing", "error", err)
+		return since
+	}
+
+	for _, build := range builds {
+		if build.Status == "succeeded" {
+			break
+		}
+		if build.Status == "failed" && build.StartTime != 0 {
+			since = time.Unix(build.StartTime, 0)
+		}
+	}
+	return since
+}
+
+// escalate applies every rule whose threshold a still red story has passed.
+// Each rule is applied once per run of failures.
+func (g *Groomer) escalate(failing map[string]bool) error {
+	for title, issue := range g.open {
+		run, ok := g.red[title]
+		if !ok || !failing[title] {
+			continue
+		}
+
+		since := run.Since
+		red := time.Since(since)
+		for _, rule := range g.Escalation.rules(issue.Group) {
+			if red < time.Duration(rule.After) || g.state.Escalated[title][rule.key()] {
+				continue
+			}
+
+			g.log.Info("escalating story", "story_id", issue.ID, "group", issue.Group, "red_for", red.Truncate(time.Minute))
+			job := run.Job
+			if err := g.applyRule(title, issue.Issue, job, rule); err != nil {
+				return err
+			}
+			if g.state.Escalated[title] == nil {
+				g.state.Escalated[title] = map[string]bool{}
+			}
+			g.state.Escalated[title][rule.key()] = true
+			g.stateChanged = true
+
+			event := g.buildEvent(StoryEscalated, title, issue.Issue, job)
+			event.Group = issue.Group
+			event.FailingSince = &since
+			event.Owners = rule.Notify
+			g.emit(event)
+		}
+	}
+	return nil
+}
+
+func (g *Groomer) applyRule(title string, issue Issue, job Job, rule EscalationRule) error {
+	entry := AuditEntry{StoryID: issue.ID, StoryURL: issue.URL, Title: title}
+	if labeler, ok := g.Backend.(Labeler); ok && rule.Label != "" {
+		err := labeler.AddLabel(issue.ID, rule.Label)
+		entry.Action, entry.Payload = "label", map[string]string{"label": rule.Label}
+		g.audit(entry, job, err)
+		if err != nil {
+			return err
+		}
+	}
+	if prioritizer, ok := g.Backend.(Prioritizer); ok && rule.MoveToTop {
+		err := prioritizer.MoveToTop(issue.ID)
+		entry.Action, entry.Payload = "move to top", nil
+		g.audit(entry, job, err)
+		if err != nil {
+			return err
+		}
+	}
+	if retyper, ok := g.Backend.(Retyper); ok && rule.IssueType != "" {
+		err := retyper.SetType(issue.ID, rule.IssueType)
+		entry.Action, entry.Payload = "set type", map[string]string{"type": rule.IssueType}
+		g.audit(entry, job, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package status_groomer
+
+import "time"
+
+const (
+	StoryCreated   = "story_created"
+	StoryReopened  = "story_reopened"
+	CommentAdded   = "comment_added"
+	StoryResolved  = "story_resolved"
+	FailureIgnored = "failure_ignored"
+	PollFailed     = "poll_failed"
+	FlakeDetected  = "flake_detected"
+	StoryEscalated = "story_escalated"
+	StormStarted   = "storm_started"
+	StormCleared   = "storm_cleared"
+)
+
+type Event struct {
+	Type         string     `json:"type"`
+	Time         time.Time  `json:"time"`
+	Group        string     `json:"group,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	IssueID      string     `json:"issue_id,omitempty"`
+	IssueURL     string     `json:"issue_url,omitempty"`
+	Team         string     `json:"team,omitempty"`
+	Pipeline     string     `json:"pipeline,omitempty"`
+	Job          string     `json:"job,omitempty"`
+	BuildURL     string     `json:"build_url,omitempty"`
+	FailingStep  string     `json:"failing_step,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	Flakiness    float64    `json:"flakiness,omitempty"`
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	Owners       []string   `json:"owners,omitempty"`
+	Error        string     `json:"error,omitempty"`
+
+	// failedBuild is the ID of the failed build whose failing step is looked
+	// up before the event is sent.
+	failedBuild int
+}
+
+type Notifier interface {
+	Notify(Event) error
+}
+
+// emit queues event for the notifiers. Events are sent once the groomer is
+// unlocked so that a slow notifier cannot stall it.
+func (g *Groomer) emit(event Event) {
+	event.Time = time.Now()
+	if event.Team == "" {
+		event.Team = g.Team
+	}
+
+	g.recordAction(event)
+	g.outbox = append(g.outbox, event)
+}
+
+// unlock saves the groomer's state, releases it and sends the events emitted
+// while it was held.
+func (g *Groomer) unlock() {
+	g.saveState()
+	events := g.outbox
+	g.outbox = nil
+	g.mu.Unlock()
+
+	for _, event := range events {
+		if len(g.Notifiers) > 0 {
+			g.addFailingStep(&event)
+		}
+		for _, notifier := range g.Notifiers {
+			if err := notifier.Notify(event); err != nil {
+				g.Log.Warn("failed to send notification", "event", event.Type, "story_id", event.IssueID, "error", err)
+			}
+		}
+	}
+}
+
+func (g *Groomer) buildEvent(eventType string, title string, issue Issue, job Job) Event {
+	event := Event{
+		Type:     eventType,
+		Group:    g.groupName(job),
+		Title:    title,
+		IssueID:  issue.ID,
+		IssueURL: issue.URL,
+		Pipeline: job.FinishedBuild.PipelineName,
+		Job:      job.FinishedBuild.JobName,
+		BuildURL: g.buildURL(job),
+	}
+
+	if job.FinishedBuild.Status == "failed" {
+		event.failedBuild = job.FinishedBuild.ID
+	}
+	return event
+}
+
+// addFailingStep looks up the step the failed build of event failed on. It is
+// called once the groomer is unlocked, as Concourse may be slow to answer.
+func (g *Groomer) addFailingStep(event *Event) {
+	if event.failedBuild == 0 {
+		return
+	}
+	step, err := g.Concourse.FailingStep(g.Host, event.failedBuild)
+	if err != nil {
+		g.Log.Warn("could not find the failing step", "pipeline", event.Pipeline, "job", event.Job, "build_id", event.failedBuild, "group", event.Group, "error", err)
+	}
+	event.FailingStep = step
+}
+
+// ignore announces that a failed build was deliberately left alone. Each build
+// is only announced once even though it is seen again on every poll. Builds
+// are remembered per job, as a group can have several jobs ignored at once.
+func (g *Groomer) ignore(title string, issue Issue, job Job, reason string) {
+	key := job.FinishedBuild.PipelineName + "/" + job.FinishedBuild.JobName
+	buildURL := g.buildURL(job)
+	if g.state.Ignored[key] == buildURL {
+		return
+	}
+	g.state.Ignored[key] = buildURL
+	g.stateChanged = true
+
+	event := g.buildEvent(FailureIgnored, title, issue, job)
+	event.Reason = reason
+	g.emit(event)
+}
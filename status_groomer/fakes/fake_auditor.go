@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeAuditor struct {
+	RecordStub        func(status_groomer.AuditEntry) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 status_groomer.AuditEntry
+	}
+	recordReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeAuditor) Record(arg1 status_groomer.AuditEntry) error {
+	fake.recordMutex.Lock()
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 status_groomer.AuditEntry
+	}{arg1})
+	fake.recordMutex.Unlock()
+	if fake.RecordStub != nil {
+		return fake.RecordStub(arg1)
+	} else {
+		return fake.recordReturns.result1
+	}
+}
+
+func (fake *FakeAuditor) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeAuditor) RecordArgsForCall(i int) status_groomer.AuditEntry {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.recordArgsForCall[i].arg1
+}
+
+func (fake *FakeAuditor) RecordReturns(result1 error) {
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.Auditor = new(FakeAuditor)
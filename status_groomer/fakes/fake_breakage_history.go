@@ -0,0 +1,85 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeBreakageHistory struct {
+	RecordStub        func(status_groomer.BreakageEvent) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 status_groomer.BreakageEvent
+	}
+	recordReturns struct {
+		result1 error
+	}
+	BrokenStub        func() (map[string]bool, error)
+	brokenMutex       sync.RWMutex
+	brokenArgsForCall []struct{}
+	brokenReturns     struct {
+		result1 map[string]bool
+		result2 error
+	}
+}
+
+func (fake *FakeBreakageHistory) Record(arg1 status_groomer.BreakageEvent) error {
+	fake.recordMutex.Lock()
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 status_groomer.BreakageEvent
+	}{arg1})
+	fake.recordMutex.Unlock()
+	if fake.RecordStub != nil {
+		return fake.RecordStub(arg1)
+	} else {
+		return fake.recordReturns.result1
+	}
+}
+
+func (fake *FakeBreakageHistory) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeBreakageHistory) RecordArgsForCall(i int) status_groomer.BreakageEvent {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.recordArgsForCall[i].arg1
+}
+
+func (fake *FakeBreakageHistory) RecordReturns(result1 error) {
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBreakageHistory) Broken() (map[string]bool, error) {
+	fake.brokenMutex.Lock()
+	fake.brokenArgsForCall = append(fake.brokenArgsForCall, struct{}{})
+	fake.brokenMutex.Unlock()
+	if fake.BrokenStub != nil {
+		return fake.BrokenStub()
+	} else {
+		return fake.brokenReturns.result1, fake.brokenReturns.result2
+	}
+}
+
+func (fake *FakeBreakageHistory) BrokenCallCount() int {
+	fake.brokenMutex.RLock()
+	defer fake.brokenMutex.RUnlock()
+	return len(fake.brokenArgsForCall)
+}
+
+func (fake *FakeBreakageHistory) BrokenReturns(result1 map[string]bool, result2 error) {
+	fake.BrokenStub = nil
+	fake.brokenReturns = struct {
+		result1 map[string]bool
+		result2 error
+	}{result1, result2}
+}
+
+var _ status_groomer.BreakageHistory = new(FakeBreakageHistory)
@@ -0,0 +1,107 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeBuildHistory struct {
+	JobBuildsStub        func(string, string, string, string, int) ([]status_groomer.Build, error)
+	jobBuildsMutex       sync.RWMutex
+	jobBuildsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}
+	jobBuildsReturns struct {
+		result1 []status_groomer.Build
+		result2 error
+	}
+	BuildInputsStub        func(string, int) (string, error)
+	buildInputsMutex       sync.RWMutex
+	buildInputsArgsForCall []struct {
+		arg1 string
+		arg2 int
+	}
+	buildInputsReturns struct {
+		result1 string
+		result2 error
+	}
+}
+
+func (fake *FakeBuildHistory) JobBuilds(arg1 string, arg2 string, arg3 string, arg4 string, arg5 int) ([]status_groomer.Build, error) {
+	fake.jobBuildsMutex.Lock()
+	fake.jobBuildsArgsForCall = append(fake.jobBuildsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	fake.jobBuildsMutex.Unlock()
+	if fake.JobBuildsStub != nil {
+		return fake.JobBuildsStub(arg1, arg2, arg3, arg4, arg5)
+	} else {
+		return fake.jobBuildsReturns.result1, fake.jobBuildsReturns.result2
+	}
+}
+
+func (fake *FakeBuildHistory) JobBuildsCallCount() int {
+	fake.jobBuildsMutex.RLock()
+	defer fake.jobBuildsMutex.RUnlock()
+	return len(fake.jobBuildsArgsForCall)
+}
+
+func (fake *FakeBuildHistory) JobBuildsArgsForCall(i int) (string, string, string, string, int) {
+	fake.jobBuildsMutex.RLock()
+	defer fake.jobBuildsMutex.RUnlock()
+	return fake.jobBuildsArgsForCall[i].arg1, fake.jobBuildsArgsForCall[i].arg2, fake.jobBuildsArgsForCall[i].arg3, fake.jobBuildsArgsForCall[i].arg4, fake.jobBuildsArgsForCall[i].arg5
+}
+
+func (fake *FakeBuildHistory) JobBuildsReturns(result1 []status_groomer.Build, result2 error) {
+	fake.JobBuildsStub = nil
+	fake.jobBuildsReturns = struct {
+		result1 []status_groomer.Build
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBuildHistory) BuildInputs(arg1 string, arg2 int) (string, error) {
+	fake.buildInputsMutex.Lock()
+	fake.buildInputsArgsForCall = append(fake.buildInputsArgsForCall, struct {
+		arg1 string
+		arg2 int
+	}{arg1, arg2})
+	fake.buildInputsMutex.Unlock()
+	if fake.BuildInputsStub != nil {
+		return fake.BuildInputsStub(arg1, arg2)
+	} else {
+		return fake.buildInputsReturns.result1, fake.buildInputsReturns.result2
+	}
+}
+
+func (fake *FakeBuildHistory) BuildInputsCallCount() int {
+	fake.buildInputsMutex.RLock()
+	defer fake.buildInputsMutex.RUnlock()
+	return len(fake.buildInputsArgsForCall)
+}
+
+func (fake *FakeBuildHistory) BuildInputsArgsForCall(i int) (string, int) {
+	fake.buildInputsMutex.RLock()
+	defer fake.buildInputsMutex.RUnlock()
+	return fake.buildInputsArgsForCall[i].arg1, fake.buildInputsArgsForCall[i].arg2
+}
+
+func (fake *FakeBuildHistory) BuildInputsReturns(result1 string, result2 error) {
+	fake.BuildInputsStub = nil
+	fake.buildInputsReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+var _ status_groomer.BuildHistory = new(FakeBuildHistory)
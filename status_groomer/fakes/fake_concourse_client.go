@@ -18,6 +18,16 @@ type FakeConcourseClient struct {
 		result1 []string
 		result2 error
 	}
+	FailingStepStub        func(string, int) (string, error)
+	failingStepMutex       sync.RWMutex
+	failingStepArgsForCall []struct {
+		arg1 string
+		arg2 int
+	}
+	failingStepReturns struct {
+		result1 string
+		result2 error
+	}
 }
 
 func (fake *FakeConcourseClient) GetJobURLs(arg1 string, arg2 string) ([]string, error) {
@@ -54,4 +64,38 @@ func (fake *FakeConcourseClient) GetJobURLsReturns(result1 []string, result2 err
 	}{result1, result2}
 }
 
+func (fake *FakeConcourseClient) FailingStep(arg1 string, arg2 int) (string, error) {
+	fake.failingStepMutex.Lock()
+	fake.failingStepArgsForCall = append(fake.failingStepArgsForCall, struct {
+		arg1 string
+		arg2 int
+	}{arg1, arg2})
+	fake.failingStepMutex.Unlock()
+	if fake.FailingStepStub != nil {
+		return fake.FailingStepStub(arg1, arg2)
+	} else {
+		return fake.failingStepReturns.result1, fake.failingStepReturns.result2
+	}
+}
+
+func (fake *FakeConcourseClient) FailingStepCallCount() int {
+	fake.failingStepMutex.RLock()
+	defer fake.failingStepMutex.RUnlock()
+	return len(fake.failingStepArgsForCall)
+}
+
+func (fake *FakeConcourseClient) FailingStepArgsForCall(i int) (string, int) {
+	fake.failingStepMutex.RLock()
+	defer fake.failingStepMutex.RUnlock()
+	return fake.failingStepArgsForCall[i].arg1, fake.failingStepArgsForCall[i].arg2
+}
+
+func (fake *FakeConcourseClient) FailingStepReturns(result1 string, result2 error) {
+	fake.FailingStepStub = nil
+	fake.failingStepReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 var _ status_groomer.ConcourseClient = new(FakeConcourseClient)
@@ -0,0 +1,261 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeIssueBackend struct {
+	FindOpenIssueStub        func(string) (*status_groomer.Issue, error)
+	findOpenIssueMutex       sync.RWMutex
+	findOpenIssueArgsForCall []struct {
+		arg1 string
+	}
+	findOpenIssueReturns struct {
+		result1 *status_groomer.Issue
+		result2 error
+	}
+	CreateIssueStub        func(status_groomer.NewIssue) (status_groomer.Issue, error)
+	createIssueMutex       sync.RWMutex
+	createIssueArgsForCall []struct {
+		arg1 status_groomer.NewIssue
+	}
+	createIssueReturns struct {
+		result1 status_groomer.Issue
+		result2 error
+	}
+	CommentsStub        func(string) ([]string, error)
+	commentsMutex       sync.RWMutex
+	commentsArgsForCall []struct {
+		arg1 string
+	}
+	commentsReturns struct {
+		result1 []string
+		result2 error
+	}
+	AddCommentStub        func(string, status_groomer.Comment) error
+	addCommentMutex       sync.RWMutex
+	addCommentArgsForCall []struct {
+		arg1 string
+		arg2 status_groomer.Comment
+	}
+	addCommentReturns struct {
+		result1 error
+	}
+	ResolveIssueStub        func(string) error
+	resolveIssueMutex       sync.RWMutex
+	resolveIssueArgsForCall []struct {
+		arg1 string
+	}
+	resolveIssueReturns struct {
+		result1 error
+	}
+	ReopenIssueStub        func(string) error
+	reopenIssueMutex       sync.RWMutex
+	reopenIssueArgsForCall []struct {
+		arg1 string
+	}
+	reopenIssueReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeIssueBackend) FindOpenIssue(arg1 string) (*status_groomer.Issue, error) {
+	fake.findOpenIssueMutex.Lock()
+	fake.findOpenIssueArgsForCall = append(fake.findOpenIssueArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.findOpenIssueMutex.Unlock()
+	if fake.FindOpenIssueStub != nil {
+		return fake.FindOpenIssueStub(arg1)
+	} else {
+		return fake.findOpenIssueReturns.result1, fake.findOpenIssueReturns.result2
+	}
+}
+
+func (fake *FakeIssueBackend) FindOpenIssueCallCount() int {
+	fake.findOpenIssueMutex.RLock()
+	defer fake.findOpenIssueMutex.RUnlock()
+	return len(fake.findOpenIssueArgsForCall)
+}
+
+func (fake *FakeIssueBackend) FindOpenIssueArgsForCall(i int) string {
+	fake.findOpenIssueMutex.RLock()
+	defer fake.findOpenIssueMutex.RUnlock()
+	return fake.findOpenIssueArgsForCall[i].arg1
+}
+
+func (fake *FakeIssueBackend) FindOpenIssueReturns(result1 *status_groomer.Issue, result2 error) {
+	fake.FindOpenIssueStub = nil
+	fake.findOpenIssueReturns = struct {
+		result1 *status_groomer.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIssueBackend) CreateIssue(arg1 status_groomer.NewIssue) (status_groomer.Issue, error) {
+	fake.createIssueMutex.Lock()
+	fake.createIssueArgsForCall = append(fake.createIssueArgsForCall, struct {
+		arg1 status_groomer.NewIssue
+	}{arg1})
+	fake.createIssueMutex.Unlock()
+	if fake.CreateIssueStub != nil {
+		return fake.CreateIssueStub(arg1)
+	} else {
+		return fake.createIssueReturns.result1, fake.createIssueReturns.result2
+	}
+}
+
+func (fake *FakeIssueBackend) CreateIssueCallCount() int {
+	fake.createIssueMutex.RLock()
+	defer fake.createIssueMutex.RUnlock()
+	return len(fake.createIssueArgsForCall)
+}
+
+func (fake *FakeIssueBackend) CreateIssueArgsForCall(i int) status_groomer.NewIssue {
+	fake.createIssueMutex.RLock()
+	defer fake.createIssueMutex.RUnlock()
+	return fake.createIssueArgsForCall[i].arg1
+}
+
+func (fake *FakeIssueBackend) CreateIssueReturns(result1 status_groomer.Issue, result2 error) {
+	fake.CreateIssueStub = nil
+	fake.createIssueReturns = struct {
+		result1 status_groomer.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIssueBackend) Comments(arg1 string) ([]string, error) {
+	fake.commentsMutex.Lock()
+	fake.commentsArgsForCall = append(fake.commentsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.commentsMutex.Unlock()
+	if fake.CommentsStub != nil {
+		return fake.CommentsStub(arg1)
+	} else {
+		return fake.commentsReturns.result1, fake.commentsReturns.result2
+	}
+}
+
+func (fake *FakeIssueBackend) CommentsCallCount() int {
+	fake.commentsMutex.RLock()
+	defer fake.commentsMutex.RUnlock()
+	return len(fake.commentsArgsForCall)
+}
+
+func (fake *FakeIssueBackend) CommentsArgsForCall(i int) string {
+	fake.commentsMutex.RLock()
+	defer fake.commentsMutex.RUnlock()
+	return fake.commentsArgsForCall[i].arg1
+}
+
+func (fake *FakeIssueBackend) CommentsReturns(result1 []string, result2 error) {
+	fake.CommentsStub = nil
+	fake.commentsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIssueBackend) AddComment(arg1 string, arg2 status_groomer.Comment) error {
+	fake.addCommentMutex.Lock()
+	fake.addCommentArgsForCall = append(fake.addCommentArgsForCall, struct {
+		arg1 string
+		arg2 status_groomer.Comment
+	}{arg1, arg2})
+	fake.addCommentMutex.Unlock()
+	if fake.AddCommentStub != nil {
+		return fake.AddCommentStub(arg1, arg2)
+	} else {
+		return fake.addCommentReturns.result1
+	}
+}
+
+func (fake *FakeIssueBackend) AddCommentCallCount() int {
+	fake.addCommentMutex.RLock()
+	defer fake.addCommentMutex.RUnlock()
+	return len(fake.addCommentArgsForCall)
+}
+
+func (fake *FakeIssueBackend) AddCommentArgsForCall(i int) (string, status_groomer.Comment) {
+	fake.addCommentMutex.RLock()
+	defer fake.addCommentMutex.RUnlock()
+	return fake.addCommentArgsForCall[i].arg1, fake.addCommentArgsForCall[i].arg2
+}
+
+func (fake *FakeIssueBackend) AddCommentReturns(result1 error) {
+	fake.AddCommentStub = nil
+	fake.addCommentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIssueBackend) ResolveIssue(arg1 string) error {
+	fake.resolveIssueMutex.Lock()
+	fake.resolveIssueArgsForCall = append(fake.resolveIssueArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.resolveIssueMutex.Unlock()
+	if fake.ResolveIssueStub != nil {
+		return fake.ResolveIssueStub(arg1)
+	} else {
+		return fake.resolveIssueReturns.result1
+	}
+}
+
+func (fake *FakeIssueBackend) ResolveIssueCallCount() int {
+	fake.resolveIssueMutex.RLock()
+	defer fake.resolveIssueMutex.RUnlock()
+	return len(fake.resolveIssueArgsForCall)
+}
+
+func (fake *FakeIssueBackend) ResolveIssueArgsForCall(i int) string {
+	fake.resolveIssueMutex.RLock()
+	defer fake.resolveIssueMutex.RUnlock()
+	return fake.resolveIssueArgsForCall[i].arg1
+}
+
+func (fake *FakeIssueBackend) ResolveIssueReturns(result1 error) {
+	fake.ResolveIssueStub = nil
+	fake.resolveIssueReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIssueBackend) ReopenIssue(arg1 string) error {
+	fake.reopenIssueMutex.Lock()
+	fake.reopenIssueArgsForCall = append(fake.reopenIssueArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.reopenIssueMutex.Unlock()
+	if fake.ReopenIssueStub != nil {
+		return fake.ReopenIssueStub(arg1)
+	} else {
+		return fake.reopenIssueReturns.result1
+	}
+}
+
+func (fake *FakeIssueBackend) ReopenIssueCallCount() int {
+	fake.reopenIssueMutex.RLock()
+	defer fake.reopenIssueMutex.RUnlock()
+	return len(fake.reopenIssueArgsForCall)
+}
+
+func (fake *FakeIssueBackend) ReopenIssueArgsForCall(i int) string {
+	fake.reopenIssueMutex.RLock()
+	defer fake.reopenIssueMutex.RUnlock()
+	return fake.reopenIssueArgsForCall[i].arg1
+}
+
+func (fake *FakeIssueBackend) ReopenIssueReturns(result1 error) {
+	fake.ReopenIssueStub = nil
+	fake.reopenIssueReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.IssueBackend = new(FakeIssueBackend)
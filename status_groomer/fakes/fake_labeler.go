@@ -0,0 +1,55 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeLabeler struct {
+	AddLabelStub        func(string, string) error
+	addLabelMutex       sync.RWMutex
+	addLabelArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	addLabelReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeLabeler) AddLabel(arg1 string, arg2 string) error {
+	fake.addLabelMutex.Lock()
+	fake.addLabelArgsForCall = append(fake.addLabelArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.addLabelMutex.Unlock()
+	if fake.AddLabelStub != nil {
+		return fake.AddLabelStub(arg1, arg2)
+	} else {
+		return fake.addLabelReturns.result1
+	}
+}
+
+func (fake *FakeLabeler) AddLabelCallCount() int {
+	fake.addLabelMutex.RLock()
+	defer fake.addLabelMutex.RUnlock()
+	return len(fake.addLabelArgsForCall)
+}
+
+func (fake *FakeLabeler) AddLabelArgsForCall(i int) (string, string) {
+	fake.addLabelMutex.RLock()
+	defer fake.addLabelMutex.RUnlock()
+	return fake.addLabelArgsForCall[i].arg1, fake.addLabelArgsForCall[i].arg2
+}
+
+func (fake *FakeLabeler) AddLabelReturns(result1 error) {
+	fake.AddLabelStub = nil
+	fake.addLabelReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.Labeler = new(FakeLabeler)
@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeNotifier struct {
+	NotifyStub        func(status_groomer.Event) error
+	notifyMutex       sync.RWMutex
+	notifyArgsForCall []struct {
+		arg1 status_groomer.Event
+	}
+	notifyReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeNotifier) Notify(arg1 status_groomer.Event) error {
+	fake.notifyMutex.Lock()
+	fake.notifyArgsForCall = append(fake.notifyArgsForCall, struct {
+		arg1 status_groomer.Event
+	}{arg1})
+	fake.notifyMutex.Unlock()
+	if fake.NotifyStub != nil {
+		return fake.NotifyStub(arg1)
+	} else {
+		return fake.notifyReturns.result1
+	}
+}
+
+func (fake *FakeNotifier) NotifyCallCount() int {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	return len(fake.notifyArgsForCall)
+}
+
+func (fake *FakeNotifier) NotifyArgsForCall(i int) status_groomer.Event {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	return fake.notifyArgsForCall[i].arg1
+}
+
+func (fake *FakeNotifier) NotifyReturns(result1 error) {
+	fake.NotifyStub = nil
+	fake.notifyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.Notifier = new(FakeNotifier)
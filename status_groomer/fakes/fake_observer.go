@@ -0,0 +1,41 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeObserver struct {
+	CycleFinishedStub        func(status_groomer.CycleStats)
+	cycleFinishedMutex       sync.RWMutex
+	cycleFinishedArgsForCall []struct {
+		arg1 status_groomer.CycleStats
+	}
+}
+
+func (fake *FakeObserver) CycleFinished(arg1 status_groomer.CycleStats) {
+	fake.cycleFinishedMutex.Lock()
+	fake.cycleFinishedArgsForCall = append(fake.cycleFinishedArgsForCall, struct {
+		arg1 status_groomer.CycleStats
+	}{arg1})
+	fake.cycleFinishedMutex.Unlock()
+	if fake.CycleFinishedStub != nil {
+		fake.CycleFinishedStub(arg1)
+	}
+}
+
+func (fake *FakeObserver) CycleFinishedCallCount() int {
+	fake.cycleFinishedMutex.RLock()
+	defer fake.cycleFinishedMutex.RUnlock()
+	return len(fake.cycleFinishedArgsForCall)
+}
+
+func (fake *FakeObserver) CycleFinishedArgsForCall(i int) status_groomer.CycleStats {
+	fake.cycleFinishedMutex.RLock()
+	defer fake.cycleFinishedMutex.RUnlock()
+	return fake.cycleFinishedArgsForCall[i].arg1
+}
+
+var _ status_groomer.Observer = new(FakeObserver)
@@ -0,0 +1,57 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakePipelineGrouper struct {
+	PipelineGroupsStub        func(string, string) (map[string]string, error)
+	pipelineGroupsMutex       sync.RWMutex
+	pipelineGroupsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	pipelineGroupsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+}
+
+func (fake *FakePipelineGrouper) PipelineGroups(arg1 string, arg2 string) (map[string]string, error) {
+	fake.pipelineGroupsMutex.Lock()
+	fake.pipelineGroupsArgsForCall = append(fake.pipelineGroupsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.pipelineGroupsMutex.Unlock()
+	if fake.PipelineGroupsStub != nil {
+		return fake.PipelineGroupsStub(arg1, arg2)
+	} else {
+		return fake.pipelineGroupsReturns.result1, fake.pipelineGroupsReturns.result2
+	}
+}
+
+func (fake *FakePipelineGrouper) PipelineGroupsCallCount() int {
+	fake.pipelineGroupsMutex.RLock()
+	defer fake.pipelineGroupsMutex.RUnlock()
+	return len(fake.pipelineGroupsArgsForCall)
+}
+
+func (fake *FakePipelineGrouper) PipelineGroupsArgsForCall(i int) (string, string) {
+	fake.pipelineGroupsMutex.RLock()
+	defer fake.pipelineGroupsMutex.RUnlock()
+	return fake.pipelineGroupsArgsForCall[i].arg1, fake.pipelineGroupsArgsForCall[i].arg2
+}
+
+func (fake *FakePipelineGrouper) PipelineGroupsReturns(result1 map[string]string, result2 error) {
+	fake.PipelineGroupsStub = nil
+	fake.pipelineGroupsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+var _ status_groomer.PipelineGrouper = new(FakePipelineGrouper)
@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakePrioritizer struct {
+	MoveToTopStub        func(string) error
+	moveToTopMutex       sync.RWMutex
+	moveToTopArgsForCall []struct {
+		arg1 string
+	}
+	moveToTopReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakePrioritizer) MoveToTop(arg1 string) error {
+	fake.moveToTopMutex.Lock()
+	fake.moveToTopArgsForCall = append(fake.moveToTopArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.moveToTopMutex.Unlock()
+	if fake.MoveToTopStub != nil {
+		return fake.MoveToTopStub(arg1)
+	} else {
+		return fake.moveToTopReturns.result1
+	}
+}
+
+func (fake *FakePrioritizer) MoveToTopCallCount() int {
+	fake.moveToTopMutex.RLock()
+	defer fake.moveToTopMutex.RUnlock()
+	return len(fake.moveToTopArgsForCall)
+}
+
+func (fake *FakePrioritizer) MoveToTopArgsForCall(i int) string {
+	fake.moveToTopMutex.RLock()
+	defer fake.moveToTopMutex.RUnlock()
+	return fake.moveToTopArgsForCall[i].arg1
+}
+
+func (fake *FakePrioritizer) MoveToTopReturns(result1 error) {
+	fake.MoveToTopStub = nil
+	fake.moveToTopReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.Prioritizer = new(FakePrioritizer)
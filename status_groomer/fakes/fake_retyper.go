@@ -0,0 +1,55 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeRetyper struct {
+	SetTypeStub        func(string, string) error
+	setTypeMutex       sync.RWMutex
+	setTypeArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	setTypeReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeRetyper) SetType(arg1 string, arg2 string) error {
+	fake.setTypeMutex.Lock()
+	fake.setTypeArgsForCall = append(fake.setTypeArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	fake.setTypeMutex.Unlock()
+	if fake.SetTypeStub != nil {
+		return fake.SetTypeStub(arg1, arg2)
+	} else {
+		return fake.setTypeReturns.result1
+	}
+}
+
+func (fake *FakeRetyper) SetTypeCallCount() int {
+	fake.setTypeMutex.RLock()
+	defer fake.setTypeMutex.RUnlock()
+	return len(fake.setTypeArgsForCall)
+}
+
+func (fake *FakeRetyper) SetTypeArgsForCall(i int) (string, string) {
+	fake.setTypeMutex.RLock()
+	defer fake.setTypeMutex.RUnlock()
+	return fake.setTypeArgsForCall[i].arg1, fake.setTypeArgsForCall[i].arg2
+}
+
+func (fake *FakeRetyper) SetTypeReturns(result1 error) {
+	fake.SetTypeStub = nil
+	fake.setTypeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.Retyper = new(FakeRetyper)
@@ -0,0 +1,85 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/jaresty/concourse-tracker-bot/status_groomer"
+)
+
+type FakeStateStore struct {
+	LoadStub        func() (status_groomer.State, error)
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct{}
+	loadReturns     struct {
+		result1 status_groomer.State
+		result2 error
+	}
+	SaveStub        func(status_groomer.State) error
+	saveMutex       sync.RWMutex
+	saveArgsForCall []struct {
+		arg1 status_groomer.State
+	}
+	saveReturns struct {
+		result1 error
+	}
+}
+
+func (fake *FakeStateStore) Load() (status_groomer.State, error) {
+	fake.loadMutex.Lock()
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct{}{})
+	fake.loadMutex.Unlock()
+	if fake.LoadStub != nil {
+		return fake.LoadStub()
+	} else {
+		return fake.loadReturns.result1, fake.loadReturns.result2
+	}
+}
+
+func (fake *FakeStateStore) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeStateStore) LoadReturns(result1 status_groomer.State, result2 error) {
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 status_groomer.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) Save(arg1 status_groomer.State) error {
+	fake.saveMutex.Lock()
+	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
+		arg1 status_groomer.State
+	}{arg1})
+	fake.saveMutex.Unlock()
+	if fake.SaveStub != nil {
+		return fake.SaveStub(arg1)
+	} else {
+		return fake.saveReturns.result1
+	}
+}
+
+func (fake *FakeStateStore) SaveCallCount() int {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return len(fake.saveArgsForCall)
+}
+
+func (fake *FakeStateStore) SaveArgsForCall(i int) status_groomer.State {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return fake.saveArgsForCall[i].arg1
+}
+
+func (fake *FakeStateStore) SaveReturns(result1 error) {
+	fake.SaveStub = nil
+	fake.saveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+var _ status_groomer.StateStore = new(FakeStateStore)
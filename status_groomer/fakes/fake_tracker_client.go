@@ -29,6 +29,17 @@ type FakeTrackerClient struct {
 		result1 tracker.Story
 		result2 error
 	}
+	UpdateStoryStub        func(int, int, tracker.Story) (tracker.Story, error)
+	updateStoryMutex       sync.RWMutex
+	updateStoryArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 tracker.Story
+	}
+	updateStoryReturns struct {
+		result1 tracker.Story
+		result2 error
+	}
 	ListCommentsStub        func(int, int) ([]tracker.Comment, error)
 	listCommentsMutex       sync.RWMutex
 	listCommentsArgsForCall []struct {
@@ -49,6 +60,16 @@ type FakeTrackerClient struct {
 	addCommentReturns struct {
 		result1 error
 	}
+	AddLabelStub        func(int, int, string) error
+	addLabelMutex       sync.RWMutex
+	addLabelArgsForCall []struct {
+		arg1 int
+		arg2 int
+		arg3 string
+	}
+	addLabelReturns struct {
+		result1 error
+	}
 }
 
 func (fake *FakeTrackerClient) Stories(arg1 int, arg2 string) ([]tracker.Story, error) {
@@ -119,6 +140,41 @@ func (fake *FakeTrackerClient) CreateStoryReturns(result1 tracker.Story, result2
 	}{result1, result2}
 }
 
+func (fake *FakeTrackerClient) UpdateStory(arg1 int, arg2 int, arg3 tracker.Story) (tracker.Story, error) {
+	fake.updateStoryMutex.Lock()
+	fake.updateStoryArgsForCall = append(fake.updateStoryArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 tracker.Story
+	}{arg1, arg2, arg3})
+	fake.updateStoryMutex.Unlock()
+	if fake.UpdateStoryStub != nil {
+		return fake.UpdateStoryStub(arg1, arg2, arg3)
+	} else {
+		return fake.updateStoryReturns.result1, fake.updateStoryReturns.result2
+	}
+}
+
+func (fake *FakeTrackerClient) UpdateStoryCallCount() int {
+	fake.updateStoryMutex.RLock()
+	defer fake.updateStoryMutex.RUnlock()
+	return len(fake.updateStoryArgsForCall)
+}
+
+func (fake *FakeTrackerClient) UpdateStoryArgsForCall(i int) (int, int, tracker.Story) {
+	fake.updateStoryMutex.RLock()
+	defer fake.updateStoryMutex.RUnlock()
+	return fake.updateStoryArgsForCall[i].arg1, fake.updateStoryArgsForCall[i].arg2, fake.updateStoryArgsForCall[i].arg3
+}
+
+func (fake *FakeTrackerClient) UpdateStoryReturns(result1 tracker.Story, result2 error) {
+	fake.UpdateStoryStub = nil
+	fake.updateStoryReturns = struct {
+		result1 tracker.Story
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeTrackerClient) ListComments(arg1 int, arg2 int) ([]tracker.Comment, error) {
 	fake.listCommentsMutex.Lock()
 	fake.listCommentsArgsForCall = append(fake.listCommentsArgsForCall, struct {
@@ -187,4 +243,38 @@ func (fake *FakeTrackerClient) AddCommentReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeTrackerClient) AddLabel(arg1 int, arg2 int, arg3 string) error {
+	fake.addLabelMutex.Lock()
+	fake.addLabelArgsForCall = append(fake.addLabelArgsForCall, struct {
+		arg1 int
+		arg2 int
+		arg3 string
+	}{arg1, arg2, arg3})
+	fake.addLabelMutex.Unlock()
+	if fake.AddLabelStub != nil {
+		return fake.AddLabelStub(arg1, arg2, arg3)
+	} else {
+		return fake.addLabelReturns.result1
+	}
+}
+
+func (fake *FakeTrackerClient) AddLabelCallCount() int {
+	fake.addLabelMutex.RLock()
+	defer fake.addLabelMutex.RUnlock()
+	return len(fake.addLabelArgsForCall)
+}
+
+func (fake *FakeTrackerClient) AddLabelArgsForCall(i int) (int, int, string) {
+	fake.addLabelMutex.RLock()
+	defer fake.addLabelMutex.RUnlock()
+	return fake.addLabelArgsForCall[i].arg1, fake.addLabelArgsForCall[i].arg2, fake.addLabelArgsForCall[i].arg3
+}
+
+func (fake *FakeTrackerClient) AddLabelReturns(result1 error) {
+	fake.AddLabelStub = nil
+	fake.addLabelReturns = struct {
+		result1 error
+	}{result1}
+}
+
 var _ status_groomer.TrackerClient = new(FakeTrackerClient)
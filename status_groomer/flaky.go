@@ -0,0 +1,145 @@
+package status_groomer
+
+import (
+	"fmt"
+)
+
+// BuildHistory looks up a job's recent builds so that the groomer can tell
+// flaky jobs from broken ones.
+type BuildHistory interface {
+	JobBuilds(host, team, pipeline, job string, limit int) ([]Build, error)
+	BuildInputs(host string, buildID int) (string, error)
+}
+
+type FlakeDetection struct {
+	// Window is the number of recent builds looked at. Zero disables flake
+	// detection.
+	Window int    `yaml:"window"`
+	Label  string `yaml:"label"`
+	// Consolidate files failures of flaky jobs on a single recurring story
+	// instead of a broken build story per group.
+	Consolidate bool   `yaml:"consolidate"`
+	Title       string `yaml:"title"`
+}
+
+func (f FlakeDetection) label() string {
+	if f.Label == "" {
+		return "flaky"
+	}
+	return f.Label
+}
+
+func (f FlakeDetection) title() string {
+	if f.Title == "" {
+		return "flaky tests"
+	}
+	return f.Title
+}
+
+type flakeScore struct {
+	BuildID int
+	Score   float64
+	Flaky   bool
+}
+
+// flakiness scores how often a job flipped between failed and succeeded in
+// its recent builds. A job is flaky once a failed build was followed by a
+// passing build on the same inputs.
+func (g *Groomer) flakiness(job Job) (float64, bool) {
+	if g.History == nil || g.Flakes.Window == 0 {
+		return 0, false
+	}
+
+	build := job.FinishedBuild
+	key := build.PipelineName + "/" + build.JobName
+	if cached, ok := g.flakes[key]; ok && cached.BuildID == build.ID {
+		return cached.Score, cached.Flaky
+	}
+
+	builds, err := g.History.JobBuilds(g.Host, g.Team, build.PipelineName, build.JobName, g.Flakes.Window)
+	if err != nil {
+		g.jobLog(job).Warn("could not look up the job's history", "error", err)
+		return 0, false
+	}
+
+	outcomes := []Build{}
+	for _, b := range builds {
+		if b.Status == "failed" || b.Status == "succeeded" {
+			outcomes = append(outcomes, b)
+		}
+	}
+
+	result := flakeScore{BuildID: build.ID}
+	flips := 0
+	for i := 1; i < len(outcomes); i++ {
+		if outcomes[i].Status != outcomes[i-1].Status {
+			flips++
+		}
+	}
+	if len(outcomes) > 1 {
+		result.Score = float64(flips) / float64(len(outcomes)-1)
+	}
+
+	for i := 0; i+1 < len(outcomes) && !result.Flaky; i++ {
+		newer, older := outcomes[i], outcomes[i+1]
+		if newer.Status != "succeeded" || older.Status != "failed" {
+			continue
+		}
+		same, err := g.sameInputs(newer.ID, older.ID)
+		if err != nil {
+			g.jobLog(job).Warn("could not compare the inputs of the job's builds", "error", err)
+			return 0, false
+		}
+		result.Flaky = same
+	}
+
+	if g.flakes == nil {
+		g.flakes = map[string]flakeScore{}
+	}
+	g.flakes[key] = result
+	return result.Score, result.Flaky
+}
+
+func (g *Groomer) sameInputs(a, b int) (bool, error) {
+	if g.inputs == nil {
+		g.inputs = map[int]string{}
+	}
+	for _, id := range []int{a, b} {
+		if _, ok := g.inputs[id]; ok {
+			continue
+		}
+		inputs, err := g.History.BuildInputs(g.Host, id)
+		if err != nil {
+			return false, err
+		}
+		g.inputs[id] = inputs
+	}
+	return g.inputs[a] == g.inputs[b], nil
+}
+
+// markFlaky labels a story of a flaky job once and announces it.
+func (g *Groomer) markFlaky(title string, issue Issue, job Job, score float64) error {
+	if g.state.Labeled[issue.ID] {
+		return nil
+	}
+
+	if labeler, ok := g.Backend.(Labeler); ok {
+		g.jobLog(job).Info("labeling story", "story_id", issue.ID, "label", g.Flakes.label())
+		err := labeler.AddLabel(issue.ID, g.Flakes.label())
+		g.audit(AuditEntry{Action: "label", StoryID: issue.ID, StoryURL: issue.URL, Title: title, Payload: map[string]string{"label": g.Flakes.label()}}, job, err)
+		if err != nil {
+			return err
+		}
+	}
+	g.state.Labeled[issue.ID] = true
+	g.stateChanged = true
+
+	event := g.buildEvent(FlakeDetected, title, issue, job)
+	event.Flakiness = score
+	g.emit(event)
+	return nil
+}
+
+func flakyComment(job Job, score float64) string {
+	return fmt.Sprintf("%s/%s failed and passed again on the same inputs (flakiness %.2f):", job.FinishedBuild.PipelineName, job.FinishedBuild.JobName, score)
+}
@@ -0,0 +1,1190 @@
+package status_groomer_test
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/logging"
+	. "github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Groomer", func() {
+	var (
+		mockServer          *ghttp.Server
+		mockBackend         *fakes.FakeIssueBackend
+		mockConcourseClient *fakes.FakeConcourseClient
+		groomer             *Groomer
+		jobStatus           map[string]string
+	)
+
+	respondWithJob := func(path string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+				FinishedBuild: Build{
+					JobName:      path[1:],
+					PipelineName: "fooPipeline",
+					Status:       jobStatus[path],
+					URL:          path + "/builds/1",
+				},
+			})(w, r)
+		}
+	}
+
+	BeforeEach(func() {
+		mockServer = ghttp.NewServer()
+		mockBackend = new(fakes.FakeIssueBackend)
+		mockConcourseClient = new(fakes.FakeConcourseClient)
+		jobStatus = map[string]string{}
+
+		mockServer.RouteToHandler("GET", "/job1-groupa", respondWithJob("/job1-groupa"))
+		mockServer.RouteToHandler("GET", "/job2-groupa", respondWithJob("/job2-groupa"))
+		mockConcourseClient.GetJobURLsReturns([]string{
+			mockServer.URL() + "/job1-groupa",
+			mockServer.URL() + "/job2-groupa",
+		}, nil)
+
+		groomer = &Groomer{
+			GroupingStrategy: map[string]string{"(fooPipeline-.*-groupa)": "groupa"},
+			Host:             mockServer.URL(),
+			Team:             "main",
+			Backend:          mockBackend,
+			Concourse:        mockConcourseClient,
+			Log:              logging.NewRecorder(),
+			ResolveRecovered: true,
+		}
+	})
+
+	AfterEach(func() {
+		mockServer.Close()
+	})
+
+	Context("when a group recovers", func() {
+		BeforeEach(func() {
+			mockBackend.CreateIssueReturns(Issue{ID: "7", Title: "groupa has failed"}, nil)
+
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+		})
+
+		It("leaves the issue open while any job in the group is failing", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.ResolveIssueCallCount()).To(Equal(0))
+		})
+
+		It("resolves the issue once every job in the group is green", func() {
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.ResolveIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.ResolveIssueArgsForCall(0)).To(Equal("7"))
+		})
+
+		It("does not resolve issues when resolution is disabled", func() {
+			groomer.ResolveRecovered = false
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.ResolveIssueCallCount()).To(Equal(0))
+		})
+
+		It("reopens the resolved issue when the group fails again", func() {
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			jobStatus["/job2-groupa"] = "failed"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.ReopenIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.ReopenIssueArgsForCall(0)).To(Equal("7"))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(1))
+			issueID, comment := mockBackend.AddCommentArgsForCall(0)
+			Expect(issueID).To(Equal("7"))
+			Expect(comment.Link).To(Equal(mockServer.URL() + "//job2-groupa/builds/1"))
+		})
+	})
+
+	It("does not comment twice with the same build", func() {
+		jobStatus["/job1-groupa"] = "failed"
+		jobStatus["/job2-groupa"] = "succeeded"
+		mockBackend.FindOpenIssueReturns(&Issue{ID: "9"}, nil)
+		mockBackend.CommentsReturns([]string{"first failure " + mockServer.URL() + "//job1-groupa/builds/1"}, nil)
+
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(mockBackend.AddCommentCallCount()).To(Equal(0))
+	})
+
+	It("comments on a build whose link starts another build's link", func() {
+		jobStatus["/job1-groupa"] = "failed"
+		mockServer.RouteToHandler("GET", "/job1-groupa", func(w http.ResponseWriter, r *http.Request) {
+			ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+				FinishedBuild: Build{JobName: "job1-groupa", PipelineName: "fooPipeline", Status: "failed", URL: "/job1-groupa/builds/15"},
+			})(w, r)
+		})
+		mockBackend.FindOpenIssueReturns(&Issue{ID: "9"}, nil)
+		mockBackend.CommentsReturns([]string{
+			"first failure " + mockServer.URL() + "//job1-groupa/builds/150",
+			"[" + mockServer.URL() + "//job1-groupa/builds/15.1]",
+		}, nil)
+
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(mockBackend.AddCommentCallCount()).To(Equal(1))
+		_, comment := mockBackend.AddCommentArgsForCall(0)
+		Expect(comment.Link).To(Equal(mockServer.URL() + "//job1-groupa/builds/15"))
+
+		mockBackend.CommentsReturns([]string{"[" + mockServer.URL() + "//job1-groupa/builds/15]"}, nil)
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(mockBackend.AddCommentCallCount()).To(Equal(1))
+	})
+
+	It("files stories under the grouping rules set between cycles", func() {
+		jobStatus["/job1-groupa"] = "failed"
+		mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+
+		groomer.SetGroupingStrategy(map[string]string{"(fooPipeline-job1-.*)": "job1s"})
+		Expect(groomer.Cycle()).To(Succeed())
+
+		Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+		Expect(mockBackend.CreateIssueArgsForCall(0).Title).To(ContainSubstring("job1s"))
+	})
+
+	It("logs the job, story and cycle of each change", func() {
+		log := logging.NewRecorder()
+		groomer.Log = log
+		jobStatus["/job1-groupa"] = "failed"
+		jobStatus["/job2-groupa"] = "succeeded"
+		mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(groomer.Cycle()).To(Succeed())
+
+		entry, ok := log.Find("created story")
+		Expect(ok).To(BeTrue())
+		Expect(entry.Level).To(Equal(logging.Info))
+		Expect(entry.Fields).To(Equal(map[string]interface{}{
+			"cycle_id": 1,
+			"pipeline": "fooPipeline",
+			"job":      "job1-groupa",
+			"build_id": 0,
+			"group":    "groupa",
+			"story_id": "7",
+		}))
+		Expect(log.Entries()[len(log.Entries())-1].Fields).To(HaveKeyWithValue("cycle_id", 2))
+	})
+
+	Context("status", func() {
+		BeforeEach(func() {
+			groomer.Notifiers = []Notifier{new(fakes.FakeNotifier)}
+			mockBackend.CreateIssueReturns(Issue{ID: "7", URL: "https://issues/7"}, nil)
+			jobStatus["/job1-groupa"] = "succeeded"
+			jobStatus["/job2-groupa"] = "failed"
+		})
+
+		It("shows every job with its story, red jobs first", func() {
+			Expect(groomer.Status().LastPoll).To(BeZero())
+			Expect(groomer.Cycle()).To(Succeed())
+
+			status := groomer.Status()
+			Expect(status.Team).To(Equal("main"))
+			Expect(status.LastPoll).NotTo(BeZero())
+			Expect(status.LastPollError).To(BeEmpty())
+			Expect(status.Jobs).To(HaveLen(2))
+			Expect(status.Jobs[0].Job).To(Equal("job2-groupa"))
+			Expect(status.Jobs[0].Group).To(Equal("groupa"))
+			Expect(status.Jobs[0].Status).To(Equal("failed"))
+			Expect(status.Jobs[0].StoryID).To(Equal("7"))
+			Expect(status.Jobs[0].StoryURL).To(Equal("https://issues/7"))
+			Expect(status.Jobs[0].RedSince).NotTo(BeZero())
+			Expect(status.Jobs[1].Job).To(Equal("job1-groupa"))
+			Expect(status.Jobs[1].RedSince).To(BeZero())
+
+			Expect(status.Actions).To(HaveLen(1))
+			Expect(status.Actions[0].Type).To(Equal(StoryCreated))
+		})
+
+		It("keeps when a job first went red", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			redSince := groomer.Status().Jobs[0].RedSince
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(groomer.Status().Jobs[0].RedSince).To(Equal(redSince))
+
+			jobStatus["/job2-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+			for _, job := range groomer.Status().Jobs {
+				Expect(job.RedSince).To(BeZero())
+			}
+		})
+
+		It("shows failed polls", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			mockConcourseClient.GetJobURLsReturns(nil, errors.New("concourse is down"))
+			Expect(groomer.Cycle()).NotTo(Succeed())
+
+			status := groomer.Status()
+			Expect(status.LastPollError).To(Equal("concourse is down"))
+			Expect(status.Jobs).To(HaveLen(2))
+		})
+	})
+
+	Context("with a breakage history", func() {
+		var history *fakes.FakeBreakageHistory
+
+		BeforeEach(func() {
+			history = new(fakes.FakeBreakageHistory)
+			history.BrokenReturns(map[string]bool{}, nil)
+			groomer.Breakages = history
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+		})
+
+		It("records each failed build once and the recovery of its group", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(history.RecordCallCount()).To(Equal(1))
+			failed := history.RecordArgsForCall(0)
+			Expect(failed.Type).To(Equal(BuildFailed))
+			Expect(failed.Title).To(Equal("groupa has failed"))
+			Expect(failed.Group).To(Equal("groupa"))
+			Expect(failed.Pipeline).To(Equal("fooPipeline"))
+			Expect(failed.Job).To(Equal("job1-groupa"))
+
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(history.RecordCallCount()).To(Equal(2))
+			recovered := history.RecordArgsForCall(1)
+			Expect(recovered.Type).To(Equal(TitleRecovered))
+			Expect(recovered.Title).To(Equal("groupa has failed"))
+			Expect(recovered.Group).To(Equal("groupa"))
+		})
+
+		It("records recoveries of groups that broke before a restart", func() {
+			history.BrokenReturns(map[string]bool{"groupa has failed": true}, nil)
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(history.RecordCallCount()).To(Equal(1))
+			Expect(history.RecordArgsForCall(0).Type).To(Equal(TitleRecovered))
+		})
+
+		It("does not poll when the history cannot be read", func() {
+			history.BrokenReturns(nil, errors.New("disk full"))
+			Expect(groomer.Cycle()).To(MatchError("disk full"))
+		})
+	})
+
+	Context("with an audit log", func() {
+		var auditor *fakes.FakeAuditor
+
+		BeforeEach(func() {
+			auditor = new(fakes.FakeAuditor)
+			groomer.Audit = auditor
+			mockBackend.CreateIssueReturns(Issue{ID: "7", URL: "https://issues/7"}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+		})
+
+		It("records created stories with the build and group rule behind them", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(auditor.RecordCallCount()).To(Equal(1))
+			entry := auditor.RecordArgsForCall(0)
+			Expect(entry.Time).NotTo(BeZero())
+			Expect(entry.Action).To(Equal("create"))
+			Expect(entry.StoryID).To(Equal("7"))
+			Expect(entry.StoryURL).To(Equal("https://issues/7"))
+			Expect(entry.Title).To(Equal("groupa has failed"))
+			Expect(entry.Group).To(Equal("groupa"))
+			Expect(entry.Rule).To(Equal("(fooPipeline-.*-groupa)"))
+			Expect(entry.Pipeline).To(Equal("fooPipeline"))
+			Expect(entry.Job).To(Equal("job1-groupa"))
+			Expect(entry.BuildURL).To(Equal(mockServer.URL() + "//job1-groupa/builds/1"))
+			Expect(entry.Payload).To(Equal(NewIssue{
+				Title:   "groupa has failed",
+				Group:   "groupa",
+				Comment: Comment{Link: mockServer.URL() + "//job1-groupa/builds/1"},
+			}))
+		})
+
+		It("records comments and resolutions", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			mockBackend.CommentsReturns([]string{mockServer.URL() + "//job1-groupa/builds/1"}, nil)
+			jobStatus["/job2-groupa"] = "failed"
+			Expect(groomer.Cycle()).To(Succeed())
+			jobStatus["/job1-groupa"] = "succeeded"
+			jobStatus["/job2-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(auditor.RecordCallCount()).To(Equal(3))
+			comment := auditor.RecordArgsForCall(1)
+			Expect(comment.Action).To(Equal("comment"))
+			Expect(comment.StoryID).To(Equal("7"))
+			Expect(comment.Job).To(Equal("job2-groupa"))
+			Expect(comment.Payload).To(Equal(Comment{Link: mockServer.URL() + "//job2-groupa/builds/1"}))
+			Expect(auditor.RecordArgsForCall(2).Action).To(Equal("resolve"))
+		})
+
+		It("records changes the issue tracker rejected", func() {
+			mockBackend.CreateIssueReturns(Issue{}, errors.New("tracker is down"))
+			Expect(groomer.Cycle()).NotTo(Succeed())
+
+			entry := auditor.RecordArgsForCall(0)
+			Expect(entry.Action).To(Equal("create"))
+			Expect(entry.StoryID).To(BeEmpty())
+			Expect(entry.Error).To(Equal("tracker is down"))
+		})
+	})
+
+	Context("with notifiers", func() {
+		var mockNotifier *fakes.FakeNotifier
+
+		BeforeEach(func() {
+			mockNotifier = new(fakes.FakeNotifier)
+			groomer.Notifiers = []Notifier{mockNotifier}
+			mockConcourseClient.FailingStepReturns("run-tests", nil)
+			mockServer.RouteToHandler("GET", "/job3-groupb", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{
+						ID:           33,
+						JobName:      "job3-groupb",
+						PipelineName: "fooPipeline",
+						Status:       "failed",
+						URL:          "/job3-groupb/builds/1",
+					},
+				})(w, r)
+			})
+			mockConcourseClient.GetJobURLsReturns([]string{mockServer.URL() + "/job3-groupb"}, nil)
+		})
+
+		It("announces new stories with the failing step", func() {
+			mockBackend.CreateIssueReturns(Issue{ID: "7", URL: "https://issues/7"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockNotifier.NotifyCallCount()).To(Equal(1))
+			event := mockNotifier.NotifyArgsForCall(0)
+			Expect(event.Type).To(Equal(StoryCreated))
+			Expect(event.Title).To(Equal("fooPipeline/job3-groupb has failed"))
+			Expect(event.IssueURL).To(Equal("https://issues/7"))
+			Expect(event.BuildURL).To(Equal(mockServer.URL() + "//job3-groupb/builds/1"))
+			Expect(event.FailingStep).To(Equal("run-tests"))
+			Expect(event.Team).To(Equal("main"))
+
+			host, buildID := mockConcourseClient.FailingStepArgsForCall(0)
+			Expect(host).To(Equal(mockServer.URL()))
+			Expect(buildID).To(Equal(33))
+		})
+
+		It("sends notifications without holding up the groomer", func() {
+			release := make(chan struct{})
+			mockNotifier.NotifyStub = func(Event) error {
+				<-release
+				return nil
+			}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+
+			done := make(chan error, 1)
+			go func() { done <- groomer.Cycle() }()
+			Eventually(mockNotifier.NotifyCallCount).Should(Equal(1))
+
+			swapped := make(chan struct{})
+			go func() {
+				groomer.SetGroupingStrategy(map[string]string{})
+				close(swapped)
+			}()
+			Eventually(swapped).Should(BeClosed())
+
+			close(release)
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("looks up the failing step without holding up the groomer", func() {
+			release := make(chan struct{})
+			mockConcourseClient.FailingStepStub = func(string, int) (string, error) {
+				<-release
+				return "run-tests", nil
+			}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+
+			done := make(chan error, 1)
+			go func() { done <- groomer.Cycle() }()
+			Eventually(mockConcourseClient.FailingStepCallCount).Should(Equal(1))
+
+			swapped := make(chan struct{})
+			go func() {
+				groomer.SetGroupingStrategy(map[string]string{})
+				close(swapped)
+			}()
+			Eventually(swapped).Should(BeClosed())
+
+			close(release)
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(mockNotifier.NotifyArgsForCall(0).FailingStep).To(Equal("run-tests"))
+		})
+
+		It("announces comments on existing stories", func() {
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockNotifier.NotifyArgsForCall(0).Type).To(Equal(CommentAdded))
+		})
+
+		It("announces once that an already recorded build was ignored", func() {
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			mockBackend.CommentsReturns([]string{mockServer.URL() + "//job3-groupb/builds/1"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockNotifier.NotifyCallCount()).To(Equal(1))
+			event := mockNotifier.NotifyArgsForCall(0)
+			Expect(event.Type).To(Equal(FailureIgnored))
+			Expect(event.IssueID).To(Equal("7"))
+			Expect(event.Reason).To(Equal("build already recorded on the story"))
+		})
+
+		It("does not announce an ignored build again after a restart", func() {
+			store := new(fakes.FakeStateStore)
+			groomer.State = store
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			mockBackend.CommentsReturns([]string{mockServer.URL() + "//job3-groupb/builds/1"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			restarted := &Groomer{
+				Host:      groomer.Host,
+				Team:      groomer.Team,
+				Backend:   mockBackend,
+				Concourse: mockConcourseClient,
+				Notifiers: groomer.Notifiers,
+				Log:       logging.NewRecorder(),
+				State:     store,
+			}
+			store.LoadReturns(store.SaveArgsForCall(0), nil)
+			Expect(restarted.Cycle()).To(Succeed())
+
+			Expect(mockNotifier.NotifyCallCount()).To(Equal(1))
+		})
+
+		It("announces once each recorded build of a group with two jobs", func() {
+			groomer.GroupingStrategy = map[string]string{"(fooPipeline-.*-group.)": "all"}
+			mockConcourseClient.GetJobURLsReturns([]string{
+				mockServer.URL() + "/job3-groupb",
+				mockServer.URL() + "/job1-groupa",
+			}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			mockBackend.CommentsReturns([]string{
+				mockServer.URL() + "//job3-groupb/builds/1",
+				mockServer.URL() + "//job1-groupa/builds/1",
+			}, nil)
+			for i := 0; i < 3; i++ {
+				Expect(groomer.Cycle()).To(Succeed())
+			}
+
+			Expect(mockNotifier.NotifyCallCount()).To(Equal(2))
+			jobs := []string{mockNotifier.NotifyArgsForCall(0).Job, mockNotifier.NotifyArgsForCall(1).Job}
+			Expect(jobs).To(ConsistOf("job3-groupb", "job1-groupa"))
+		})
+
+		It("announces errored builds as ignored", func() {
+			mockServer.RouteToHandler("GET", "/job4-groupb", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{JobName: "job4-groupb", PipelineName: "fooPipeline", Status: "errored"},
+				})(w, r)
+			})
+			mockConcourseClient.GetJobURLsReturns([]string{mockServer.URL() + "/job4-groupb"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.FindOpenIssueCallCount()).To(Equal(0))
+			event := mockNotifier.NotifyArgsForCall(0)
+			Expect(event.Type).To(Equal(FailureIgnored))
+			Expect(event.Reason).To(Equal("build errored"))
+		})
+
+		It("announces failed polls", func() {
+			mockConcourseClient.GetJobURLsReturns(nil, errors.New("concourse is down"))
+			Expect(groomer.Run(0)).To(Succeed())
+
+			event := mockNotifier.NotifyArgsForCall(0)
+			Expect(event.Type).To(Equal(PollFailed))
+			Expect(event.Error).To(Equal("concourse is down"))
+		})
+
+		It("keeps grooming when a notification fails", func() {
+			mockNotifier.NotifyReturns(errors.New("webhook down"))
+			Expect(groomer.Cycle()).To(Succeed())
+		})
+	})
+
+	Context("when humans triage the story", func() {
+		BeforeEach(func() {
+			mockBackend.CreateIssueReturns(Issue{ID: "7", Title: "groupa has failed"}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+		})
+
+		It("comments when a story is accepted while the build is still failing", func() {
+			Expect(groomer.StoryAccepted("7")).To(Succeed())
+
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(1))
+			issueID, comment := mockBackend.AddCommentArgsForCall(0)
+			Expect(issueID).To(Equal("7"))
+			Expect(comment.Text).To(Equal("This story was accepted but the build is still failing:"))
+			Expect(comment.Link).To(Equal(mockServer.URL() + "//job1-groupa/builds/1"))
+
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.ReopenIssueCallCount()).To(Equal(0))
+		})
+
+		It("does not reopen an accepted story for another job that was already failing", func() {
+			jobStatus["/job2-groupa"] = "failed"
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7", Title: "groupa has failed"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(groomer.StoryAccepted("7")).To(Succeed())
+			mockBackend.FindOpenIssueReturns(nil, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.ReopenIssueCallCount()).To(Equal(0))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+		})
+
+		It("reopens an accepted story when a new build fails", func() {
+			Expect(groomer.StoryAccepted("7")).To(Succeed())
+
+			mockServer.RouteToHandler("GET", "/job1-groupa", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{JobName: "job1-groupa", PipelineName: "fooPipeline", Status: "failed", URL: "/job1-groupa/builds/2"},
+				})(w, r)
+			})
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.ReopenIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.ReopenIssueArgsForCall(0)).To(Equal("7"))
+		})
+
+		It("stays quiet when a story is accepted after the build passed", func() {
+			jobStatus["/job1-groupa"] = "succeeded"
+			groomer.ResolveRecovered = false
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(groomer.StoryAccepted("7")).To(Succeed())
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(0))
+		})
+
+		It("does not recreate a deleted story until the group passes", func() {
+			Expect(groomer.StoryDeleted("7")).To(Succeed())
+
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+			jobStatus["/job1-groupa"] = "failed"
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(2))
+		})
+
+		It("keeps commenting on a renamed story", func() {
+			Expect(groomer.StoryRenamed("7", "groupa is broken again")).To(Succeed())
+
+			jobStatus["/job2-groupa"] = "failed"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(2))
+			for i := 0; i < 2; i++ {
+				issueID, _ := mockBackend.AddCommentArgsForCall(i)
+				Expect(issueID).To(Equal("7"))
+			}
+		})
+
+		It("ignores stories it did not file", func() {
+			Expect(groomer.StoryAccepted("99")).To(Succeed())
+			Expect(groomer.StoryDeleted("99")).To(Succeed())
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("with auto grouping", func() {
+		var grouper *fakes.FakePipelineGrouper
+
+		BeforeEach(func() {
+			grouper = new(fakes.FakePipelineGrouper)
+			grouper.PipelineGroupsReturns(map[string]string{
+				"fooPipeline/job1-groupa": "fooPipeline/deploy",
+				"fooPipeline/job2-groupa": "fooPipeline/smoke",
+			}, nil)
+			groomer.Concourse = struct {
+				*fakes.FakeConcourseClient
+				*fakes.FakePipelineGrouper
+			}{mockConcourseClient, grouper}
+			groomer.AutoGroup = true
+			groomer.GroupingStrategy = map[string]string{}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "failed"
+		})
+
+		It("files jobs under the Concourse group of their pipeline", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			host, team := grouper.PipelineGroupsArgsForCall(0)
+			Expect([]string{host, team}).To(Equal([]string{mockServer.URL(), "main"}))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(2))
+			titles := []string{mockBackend.CreateIssueArgsForCall(0).Title, mockBackend.CreateIssueArgsForCall(1).Title}
+			Expect(titles).To(ConsistOf("fooPipeline/deploy has failed", "fooPipeline/smoke has failed"))
+		})
+
+		It("lets rules matching the job override its Concourse group", func() {
+			groomer.GroupingStrategy = map[string]string{"(fooPipeline-job1-.*)": "job1s"}
+			Expect(groomer.Cycle()).To(Succeed())
+
+			titles := []string{mockBackend.CreateIssueArgsForCall(0).Title, mockBackend.CreateIssueArgsForCall(1).Title}
+			Expect(titles).To(ConsistOf("job1s has failed", "fooPipeline/smoke has failed"))
+		})
+
+		It("merges the Concourse groups a rule matches", func() {
+			groomer.GroupingStrategy = map[string]string{"(fooPipeline/(deploy|smoke))": "release"}
+			Expect(groomer.Cycle()).To(Succeed())
+
+			titles := []string{mockBackend.CreateIssueArgsForCall(0).Title, mockBackend.CreateIssueArgsForCall(1).Title}
+			Expect(titles).To(ConsistOf("release has failed", "release has failed"))
+		})
+
+		It("fails the cycle when the pipeline groups cannot be fetched", func() {
+			grouper.PipelineGroupsReturns(nil, errors.New("connection refused"))
+
+			Expect(groomer.Cycle()).To(MatchError("connection refused"))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+		})
+
+		It("uses only the grouping strategy when off", func() {
+			groomer.AutoGroup = false
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(grouper.PipelineGroupsCallCount()).To(Equal(0))
+			titles := []string{mockBackend.CreateIssueArgsForCall(0).Title, mockBackend.CreateIssueArgsForCall(1).Title}
+			Expect(titles).To(ConsistOf("fooPipeline/job1-groupa has failed", "fooPipeline/job2-groupa has failed"))
+		})
+	})
+
+	Context("with flake detection", func() {
+		var (
+			history      *fakes.FakeBuildHistory
+			labeler      *fakes.FakeLabeler
+			mockNotifier *fakes.FakeNotifier
+			inputs       map[int]string
+		)
+
+		BeforeEach(func() {
+			history = new(fakes.FakeBuildHistory)
+			labeler = new(fakes.FakeLabeler)
+			mockNotifier = new(fakes.FakeNotifier)
+			inputs = map[int]string{1: "source:ref=abc", 2: "source:ref=abc", 3: "source:ref=abc"}
+			history.BuildInputsStub = func(host string, buildID int) (string, error) {
+				return inputs[buildID], nil
+			}
+			history.JobBuildsReturns([]Build{
+				{ID: 3, Status: "failed"},
+				{ID: 2, Status: "succeeded"},
+				{ID: 1, Status: "failed"},
+			}, nil)
+
+			groomer.Backend = struct {
+				*fakes.FakeIssueBackend
+				*fakes.FakeLabeler
+			}{mockBackend, labeler}
+			groomer.History = history
+			groomer.Flakes = FlakeDetection{Window: 20}
+			groomer.Notifiers = []Notifier{mockNotifier}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+		})
+
+		It("labels the story of a job that passed again on the same inputs", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			_, _, pipeline, job, limit := history.JobBuildsArgsForCall(0)
+			Expect([]interface{}{pipeline, job, limit}).To(Equal([]interface{}{"fooPipeline", "job1-groupa", 20}))
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			issueID, label := labeler.AddLabelArgsForCall(0)
+			Expect(issueID).To(Equal("7"))
+			Expect(label).To(Equal("flaky"))
+
+			event := mockNotifier.NotifyArgsForCall(mockNotifier.NotifyCallCount() - 1)
+			Expect(event.Type).To(Equal(FlakeDetected))
+			Expect(event.Flakiness).To(Equal(1.0))
+
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+		})
+
+		It("does not label a story again after a restart", func() {
+			store := new(fakes.FakeStateStore)
+			groomer.State = store
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+
+			restarted := &Groomer{
+				GroupingStrategy: groomer.GroupingStrategy,
+				Host:             groomer.Host,
+				Team:             groomer.Team,
+				Backend:          groomer.Backend,
+				Concourse:        groomer.Concourse,
+				History:          groomer.History,
+				Flakes:           groomer.Flakes,
+				Log:              logging.NewRecorder(),
+				State:            store,
+			}
+			store.LoadReturns(store.SaveArgsForCall(store.SaveCallCount()-1), nil)
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			Expect(restarted.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+		})
+
+		It("does not label jobs that only pass on new inputs", func() {
+			inputs[2] = "source:ref=def"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(labeler.AddLabelCallCount()).To(Equal(0))
+		})
+
+		It("labels an open story when its job passes on the same inputs", func() {
+			history.JobBuildsReturns([]Build{{ID: 1, Status: "failed"}}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(0))
+
+			history.JobBuildsReturns([]Build{{ID: 2, Status: "succeeded"}, {ID: 1, Status: "failed"}}, nil)
+			mockServer.RouteToHandler("GET", "/job1-groupa", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{ID: 2, JobName: "job1-groupa", PipelineName: "fooPipeline", Status: "succeeded"},
+				})(w, r)
+			})
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			Expect(mockBackend.ResolveIssueCallCount()).To(Equal(1))
+		})
+
+		It("files flaky jobs on one recurring story when consolidating", func() {
+			groomer.Flakes.Consolidate = true
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			created := mockBackend.CreateIssueArgsForCall(0)
+			Expect(created.Title).To(Equal("flaky tests"))
+			Expect(created.Group).To(BeEmpty())
+			Expect(created.Comment.Text).To(Equal("fooPipeline/job1-groupa failed and passed again on the same inputs (flakiness 1.00):"))
+		})
+	})
+
+	Context("with escalation rules", func() {
+		var (
+			history      *fakes.FakeBuildHistory
+			labeler      *fakes.FakeLabeler
+			prioritizer  *fakes.FakePrioritizer
+			retyper      *fakes.FakeRetyper
+			mockNotifier *fakes.FakeNotifier
+			started      time.Time
+		)
+
+		BeforeEach(func() {
+			history = new(fakes.FakeBuildHistory)
+			labeler = new(fakes.FakeLabeler)
+			prioritizer = new(fakes.FakePrioritizer)
+			retyper = new(fakes.FakeRetyper)
+			mockNotifier = new(fakes.FakeNotifier)
+
+			started = time.Now().Add(-30 * time.Hour).Truncate(time.Second)
+			history.JobBuildsReturns([]Build{
+				{ID: 3, Status: "failed", StartTime: time.Now().Unix()},
+				{ID: 2, Status: "errored"},
+				{ID: 1, Status: "failed", StartTime: started.Unix()},
+				{ID: 0, Status: "succeeded", StartTime: started.Add(-time.Hour).Unix()},
+			}, nil)
+
+			groomer.Backend = struct {
+				*fakes.FakeIssueBackend
+				*fakes.FakeLabeler
+				*fakes.FakePrioritizer
+				*fakes.FakeRetyper
+			}{mockBackend, labeler, prioritizer, retyper}
+			groomer.History = history
+			groomer.Notifiers = []Notifier{mockNotifier}
+			groomer.Escalation = Escalation{
+				Default: []EscalationRule{{After: Duration(time.Hour), Label: "slow"}},
+				Groups: map[string][]EscalationRule{
+					"groupa": {
+						{After: Duration(24 * time.Hour), Label: "escalated", MoveToTop: true, IssueType: "bug", Notify: []string{"@luna-owners"}},
+						{After: Duration(72 * time.Hour), Label: "abandoned"},
+					},
+				},
+			}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "succeeded"
+		})
+
+		It("applies the group's rules once the story has been red long enough", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			issueID, label := labeler.AddLabelArgsForCall(0)
+			Expect(issueID).To(Equal("7"))
+			Expect(label).To(Equal("escalated"))
+			Expect(prioritizer.MoveToTopArgsForCall(0)).To(Equal("7"))
+			issueID, issueType := retyper.SetTypeArgsForCall(0)
+			Expect(issueID).To(Equal("7"))
+			Expect(issueType).To(Equal("bug"))
+
+			event := mockNotifier.NotifyArgsForCall(mockNotifier.NotifyCallCount() - 1)
+			Expect(event.Type).To(Equal(StoryEscalated))
+			Expect(event.Owners).To(Equal([]string{"@luna-owners"}))
+			Expect(*event.FailingSince).To(BeTemporally("==", started))
+		})
+
+		It("shows the same red age on the dashboard as it escalates by", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			event := mockNotifier.NotifyArgsForCall(mockNotifier.NotifyCallCount() - 1)
+			Expect(event.Type).To(Equal(StoryEscalated))
+			for _, job := range groomer.Status().Jobs {
+				if job.Job == "job1-groupa" {
+					Expect(job.RedSince).To(BeTemporally("==", *event.FailingSince))
+				}
+			}
+		})
+
+		It("escalates each story once", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			Expect(prioritizer.MoveToTopCallCount()).To(Equal(1))
+		})
+
+		It("does not escalate a story again after a restart", func() {
+			store := new(fakes.FakeStateStore)
+			groomer.State = store
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			Expect(store.SaveCallCount()).To(Equal(1))
+
+			restarted := &Groomer{
+				GroupingStrategy: groomer.GroupingStrategy,
+				Host:             groomer.Host,
+				Team:             groomer.Team,
+				Backend:          groomer.Backend,
+				Concourse:        groomer.Concourse,
+				History:          groomer.History,
+				Escalation:       groomer.Escalation,
+				Notifiers:        groomer.Notifiers,
+				Log:              logging.NewRecorder(),
+				State:            store,
+			}
+			store.LoadReturns(store.SaveArgsForCall(0), nil)
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			notified := mockNotifier.NotifyCallCount()
+			Expect(restarted.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			Expect(prioritizer.MoveToTopCallCount()).To(Equal(1))
+			for i := notified; i < mockNotifier.NotifyCallCount(); i++ {
+				Expect(mockNotifier.NotifyArgsForCall(i).Type).NotTo(Equal(StoryEscalated))
+			}
+		})
+
+		It("does not escalate a story again when the rules are reordered", func() {
+			store := new(fakes.FakeStateStore)
+			groomer.State = store
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+
+			rules := groomer.Escalation.Groups["groupa"]
+			reordered := &Groomer{
+				GroupingStrategy: groomer.GroupingStrategy,
+				Host:             groomer.Host,
+				Team:             groomer.Team,
+				Backend:          groomer.Backend,
+				Concourse:        groomer.Concourse,
+				History:          groomer.History,
+				Escalation: Escalation{
+					Groups: map[string][]EscalationRule{"groupa": {rules[1], rules[0]}},
+				},
+				Log:   logging.NewRecorder(),
+				State: store,
+			}
+			store.LoadReturns(store.SaveArgsForCall(0), nil)
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "7"}, nil)
+			Expect(reordered.Cycle()).To(Succeed())
+
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+			Expect(prioritizer.MoveToTopCallCount()).To(Equal(1))
+		})
+
+		It("starts over once the group recovers", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			jobStatus["/job1-groupa"] = "succeeded"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			history.JobBuildsReturns([]Build{{ID: 5, Status: "failed", StartTime: time.Now().Unix()}}, nil)
+			jobStatus["/job1-groupa"] = "failed"
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.ReopenIssueCallCount()).To(Equal(1))
+			Expect(labeler.AddLabelCallCount()).To(Equal(1))
+
+			history.JobBuildsReturns([]Build{{ID: 5, Status: "failed", StartTime: started.Unix()}}, nil)
+			groomer.Escalation.Groups["groupa"][0].After = Duration(0)
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(labeler.AddLabelCallCount()).To(Equal(2))
+		})
+
+		It("uses the default rules for other groups", func() {
+			groomer.Escalation.Groups = nil
+			Expect(groomer.Cycle()).To(Succeed())
+
+			_, label := labeler.AddLabelArgsForCall(0)
+			Expect(label).To(Equal("slow"))
+			Expect(prioritizer.MoveToTopCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("with observers", func() {
+		var observer *fakes.FakeObserver
+
+		BeforeEach(func() {
+			observer = new(fakes.FakeObserver)
+			groomer.Observers = []Observer{observer}
+			mockBackend.CreateIssueReturns(Issue{ID: "7"}, nil)
+		})
+
+		It("reports the jobs checked and the failures by status and group", func() {
+			jobStatus["/job1-groupa"] = "failed"
+			jobStatus["/job2-groupa"] = "errored"
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(observer.CycleFinishedCallCount()).To(Equal(1))
+			stats := observer.CycleFinishedArgsForCall(0)
+			Expect(stats.Jobs).To(Equal(2))
+			Expect(stats.Failures).To(Equal(map[Failure]int{
+				{Status: "failed", Group: "groupa"}:  1,
+				{Status: "errored", Group: "groupa"}: 1,
+			}))
+			Expect(stats.Err).NotTo(HaveOccurred())
+			Expect(stats.Duration).To(BeNumerically(">", 0))
+		})
+
+		It("reports cycles that fail", func() {
+			mockConcourseClient.GetJobURLsReturns(nil, errors.New("concourse is down"))
+			Expect(groomer.Cycle()).NotTo(Succeed())
+
+			stats := observer.CycleFinishedArgsForCall(0)
+			Expect(stats.Err).To(MatchError("concourse is down"))
+			Expect(stats.CredentialsRejected).To(BeFalse())
+		})
+	})
+
+	Context("with a storm breaker", func() {
+		var mockNotifier *fakes.FakeNotifier
+
+		BeforeEach(func() {
+			mockNotifier = new(fakes.FakeNotifier)
+			groomer.Notifiers = []Notifier{mockNotifier}
+			groomer.Storm = StormBreaker{Groups: 2}
+			urls := []string{}
+			for _, job := range []string{"/job1-groupa", "/job3", "/job4", "/job5"} {
+				mockServer.RouteToHandler("GET", job, respondWithJob(job))
+				urls = append(urls, mockServer.URL()+job)
+				jobStatus[job] = "failed"
+			}
+			mockConcourseClient.GetJobURLsReturns(urls, nil)
+			mockBackend.CreateIssueReturns(Issue{ID: "11", Title: "widespread failure"}, nil)
+		})
+
+		It("files groups that start failing together on one story", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			created := mockBackend.CreateIssueArgsForCall(0)
+			Expect(created.Title).To(Equal("widespread failure"))
+			Expect(created.Comment.Text).To(Equal("4 jobs failed during a widespread failure:\n" +
+				"- fooPipeline/job3: fooPipeline/job3 " + mockServer.URL() + "//job3/builds/1\n" +
+				"- fooPipeline/job4: fooPipeline/job4 " + mockServer.URL() + "//job4/builds/1\n" +
+				"- fooPipeline/job5: fooPipeline/job5 " + mockServer.URL() + "//job5/builds/1\n" +
+				"- groupa: fooPipeline/job1-groupa " + mockServer.URL() + "//job1-groupa/builds/1\n" +
+				"First build:"))
+			Expect(mockNotifier.NotifyArgsForCall(0).Type).To(Equal(StormStarted))
+
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.AddCommentCallCount()).To(Equal(0))
+		})
+
+		It("files groups that start failing during the storm on its story", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			mockServer.RouteToHandler("GET", "/job6", respondWithJob("/job6"))
+			jobStatus["/job6"] = "failed"
+			urls := []string{}
+			for _, job := range []string{"/job1-groupa", "/job3", "/job4", "/job5", "/job6"} {
+				urls = append(urls, mockServer.URL()+job)
+			}
+			mockConcourseClient.GetJobURLsReturns(urls, nil)
+			mockBackend.FindOpenIssueStub = func(title string) (*Issue, error) {
+				if title == "widespread failure" {
+					return &Issue{ID: "11"}, nil
+				}
+				return nil, nil
+			}
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			issueID, comment := mockBackend.AddCommentArgsForCall(0)
+			Expect(issueID).To(Equal("11"))
+			Expect(comment.Text).To(Equal("1 jobs failed during a widespread failure:\n" +
+				"- fooPipeline/job6: fooPipeline/job6 " + mockServer.URL() + "//job6/builds/1\n" +
+				"First build:"))
+		})
+
+		It("files groups separately once the storm clears", func() {
+			Expect(groomer.Cycle()).To(Succeed())
+
+			jobStatus["/job3"] = "succeeded"
+			jobStatus["/job4"] = "succeeded"
+			mockBackend.CreateIssueReturns(Issue{ID: "12"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+
+			issueID, comment := mockBackend.AddCommentArgsForCall(0)
+			Expect(issueID).To(Equal("11"))
+			Expect(comment.Text).To(Equal("The widespread failure has cleared. Groups that are still failing get stories of their own."))
+			Expect(mockBackend.ResolveIssueArgsForCall(0)).To(Equal("11"))
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(3))
+			Expect(mockBackend.CreateIssueArgsForCall(1).Title).To(Equal("groupa has failed"))
+			Expect(mockBackend.CreateIssueArgsForCall(2).Title).To(Equal("fooPipeline/job5 has failed"))
+		})
+
+		It("does not count groups that already have a story", func() {
+			groomer.Storm.Groups = 0
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(4))
+
+			groomer.Storm.Groups = 2
+			mockBackend.FindOpenIssueReturns(&Issue{ID: "11"}, nil)
+			Expect(groomer.Cycle()).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(4))
+			Expect(mockNotifier.NotifyCallCount()).To(BeNumerically(">", 0))
+			for i := 0; i < mockNotifier.NotifyCallCount(); i++ {
+				Expect(mockNotifier.NotifyArgsForCall(i).Type).NotTo(Equal(StormStarted))
+			}
+		})
+
+		It("does not count groups whose stories were filed before a restart", func() {
+			mockBackend.FindOpenIssueStub = func(title string) (*Issue, error) {
+				return &Issue{ID: "5", Title: title}, nil
+			}
+			Expect(groomer.Cycle()).To(Succeed())
+
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+			for i := 0; i < mockNotifier.NotifyCallCount(); i++ {
+				Expect(mockNotifier.NotifyArgsForCall(i).Type).NotTo(Equal(StormStarted))
+			}
+		})
+	})
+
+	Context("when a pipeline pushes a build", func() {
+		var buildStatus []string
+
+		BeforeEach(func() {
+			buildStatus = []string{"started", "failed"}
+			groomer.PushPollInterval = time.Millisecond
+			mockServer.RouteToHandler("GET", "/api/v1/builds/12", func(w http.ResponseWriter, r *http.Request) {
+				status := buildStatus[0]
+				if len(buildStatus) > 1 {
+					buildStatus = buildStatus[1:]
+				}
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Build{
+					ID:           12,
+					JobName:      "job1-groupa",
+					PipelineName: "fooPipeline",
+					Status:       status,
+					URL:          "/teams/main/pipelines/fooPipeline/jobs/job1-groupa/builds/3",
+				})(w, r)
+			})
+		})
+
+		It("waits for the build to finish and files it without polling every job", func() {
+			Expect(groomer.ProcessBuild(BuildRef{Team: "main", Pipeline: "fooPipeline", Job: "job1-groupa", BuildID: 12})).To(Succeed())
+
+			Expect(mockConcourseClient.GetJobURLsCallCount()).To(Equal(0))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(1))
+			Expect(mockBackend.CreateIssueArgsForCall(0).Title).To(Equal("groupa has failed"))
+			Expect(mockBackend.CreateIssueArgsForCall(0).Comment.Link).To(Equal(mockServer.URL() + "//teams/main/pipelines/fooPipeline/jobs/job1-groupa/builds/3"))
+		})
+
+		It("refuses a build of another job than the one reported", func() {
+			Expect(groomer.ProcessBuild(BuildRef{Pipeline: "fooPipeline", Job: "job2-groupa", BuildID: 12})).To(MatchError("build 12 is not a build of fooPipeline/job2-groupa"))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+		})
+
+		It("escapes the pipeline and job it looks up", func() {
+			var path string
+			mockServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/teams/main/pipelines/`), func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.EscapedPath()
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{JobName: "job?", PipelineName: "foo/Pipeline", Status: "succeeded"},
+				})(w, r)
+			})
+
+			Expect(groomer.ProcessBuild(BuildRef{Pipeline: "foo/Pipeline", Job: "job?"})).To(Succeed())
+			Expect(path).To(Equal("/api/v1/teams/main/pipelines/foo%2FPipeline/jobs/job%3F"))
+		})
+
+		It("gives up on builds that do not finish in time", func() {
+			buildStatus = []string{"started"}
+			groomer.PushTimeout = 5 * time.Millisecond
+
+			Expect(groomer.ProcessBuild(BuildRef{Pipeline: "fooPipeline", Job: "job1-groupa", BuildID: 12})).To(MatchError("build 12 did not finish in time"))
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+		})
+
+		It("uses the job's latest finished build when no build is given", func() {
+			mockServer.RouteToHandler("GET", "/api/v1/teams/main/pipelines/fooPipeline/jobs/job2-groupa", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Job{
+					FinishedBuild: Build{JobName: "job2-groupa", PipelineName: "fooPipeline", Status: "succeeded"},
+				})(w, r)
+			})
+
+			Expect(groomer.ProcessBuild(BuildRef{Pipeline: "fooPipeline", Job: "job2-groupa"})).To(Succeed())
+			Expect(mockBackend.CreateIssueCallCount()).To(Equal(0))
+		})
+	})
+
+	It("passes the matched group to the backend", func() {
+		jobStatus["/job1-groupa"] = "failed"
+		jobStatus["/job2-groupa"] = "succeeded"
+
+		Expect(groomer.Cycle()).To(Succeed())
+		Expect(mockBackend.CreateIssueArgsForCall(0).Group).To(Equal("groupa"))
+	})
+})
@@ -0,0 +1,24 @@
+package status_groomer
+
+import "time"
+
+// CycleStats describes a finished poll of every job.
+type CycleStats struct {
+	Started  time.Time
+	Duration time.Duration
+	Jobs     int
+	// Failures counts the jobs whose latest build did not succeed.
+	Failures            map[Failure]int
+	Err                 error
+	CredentialsRejected bool
+}
+
+type Failure struct {
+	Status string
+	Group  string
+}
+
+// Observer is told about every poll cycle, whether or not it succeeded.
+type Observer interface {
+	CycleFinished(CycleStats)
+}
@@ -0,0 +1,105 @@
+package status_groomer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// BuildRef identifies a build reported to the bot by a pipeline rather than
+// found by polling.
+type BuildRef struct {
+	Team     string `json:"team"`
+	Pipeline string `json:"pipeline"`
+	Job      string `json:"job"`
+	BuildID  int    `json:"build_id"`
+}
+
+// ProcessBuild handles a single reported build straight away. A pipeline
+// usually reports from an on_failure hook, before the build has finished,
+// so the build is polled until it reaches a final status. A build that is not
+// of the reported job is refused.
+func (g *Groomer) ProcessBuild(ref BuildRef) error {
+	var job Job
+	var err error
+	if ref.BuildID != 0 {
+		job.FinishedBuild, err = g.awaitBuild(ref)
+	} else {
+		job, err = g.fetchJob(fmt.Sprintf("%s/api/v1/teams/%s/pipelines/%s/jobs/%s", g.Host, url.PathEscape(g.Team), url.PathEscape(ref.Pipeline), url.PathEscape(ref.Job)))
+	}
+	if err != nil {
+		return err
+	}
+
+	g.lock()
+	defer g.unlock()
+	g.log = g.Log
+
+	for i, latest := range g.latest {
+		if latest.FinishedBuild.PipelineName == job.FinishedBuild.PipelineName && latest.FinishedBuild.JobName == job.FinishedBuild.JobName {
+			g.latest[i] = job
+		}
+	}
+	defer g.snapshot(time.Time{}, nil)
+
+	title := g.storyName(job)
+	switch job.FinishedBuild.Status {
+	case "failed":
+		return g.handleFailedBuild(title, job)
+	case "errored", "aborted":
+		g.ignore(title, Issue{}, job, fmt.Sprintf("build %s", job.FinishedBuild.Status))
+	}
+	return nil
+}
+
+func (g *Groomer) awaitBuild(ref BuildRef) (Build, error) {
+	interval := g.PushPollInterval
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	deadline := time.Now().Add(g.PushTimeout)
+	if g.PushTimeout == 0 {
+		deadline = time.Now().Add(30 * time.Minute)
+	}
+
+	for {
+		build, err := g.fetchBuild(fmt.Sprintf("%s/api/v1/builds/%d", g.Host, ref.BuildID))
+		if err != nil {
+			return Build{}, err
+		}
+		if build.PipelineName != ref.Pipeline || build.JobName != ref.Job || (build.TeamName != "" && build.TeamName != g.Team) {
+			return Build{}, fmt.Errorf("build %d is not a build of %s/%s", ref.BuildID, ref.Pipeline, ref.Job)
+		}
+
+		switch build.Status {
+		case "pending", "started":
+		default:
+			return build, nil
+		}
+
+		if time.Now().After(deadline) {
+			return Build{}, fmt.Errorf("build %d did not finish in time", ref.BuildID)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (g *Groomer) fetchBuild(url string) (Build, error) {
+	res, err := g.httpClient().Get(url)
+	if err != nil {
+		return Build{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Build{}, fmt.Errorf("%s returned %s", url, res.Status)
+	}
+
+	build := Build{}
+	if err := json.NewDecoder(res.Body).Decode(&build); err != nil {
+		return Build{}, err
+	}
+	return build, nil
+}
@@ -0,0 +1,55 @@
+package status_groomer
+
+// State is what the groomer remembers about its stories across restarts and
+// runs of `once`: the escalation rules applied to each title, the stories
+// labeled flaky and the last build announced as ignored for each job.
+type State struct {
+	Escalated map[string]map[string]bool `json:"escalated,omitempty"`
+	Labeled   map[string]bool            `json:"labeled,omitempty"`
+	Ignored   map[string]string          `json:"ignored,omitempty"`
+}
+
+// StateStore persists State so that a restarted groomer does not escalate,
+// label or announce the same story again.
+type StateStore interface {
+	Load() (State, error)
+	Save(State) error
+}
+
+// lock takes the groomer's lock, loading its state on first use.
+func (g *Groomer) lock() {
+	g.mu.Lock()
+	if g.stateLoaded {
+		return
+	}
+	g.stateLoaded = true
+
+	var state State
+	if g.State != nil {
+		var err error
+		if state, err = g.State.Load(); err != nil {
+			g.Log.Error("failed to load the groomer state, starting afresh", "error", err)
+		}
+	}
+	if state.Escalated == nil {
+		state.Escalated = map[string]map[string]bool{}
+	}
+	if state.Labeled == nil {
+		state.Labeled = map[string]bool{}
+	}
+	if state.Ignored == nil {
+		state.Ignored = map[string]string{}
+	}
+	g.state = state
+}
+
+func (g *Groomer) saveState() {
+	if !g.stateChanged || g.State == nil {
+		return
+	}
+	if err := g.State.Save(g.state); err != nil {
+		g.Log.Error("failed to save the groomer state", "error", err)
+		return
+	}
+	g.stateChanged = false
+}
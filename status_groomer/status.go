@@ -0,0 +1,100 @@
+package status_groomer
+
+import (
+	"sort"
+	"time"
+)
+
+const recentActions = 50
+
+// JobStatus is the latest build of a watched job and the story it is filed
+// on, if any.
+type JobStatus struct {
+	Pipeline string    `json:"pipeline"`
+	Job      string    `json:"job"`
+	Group    string    `json:"group,omitempty"`
+	Status   string    `json:"status"`
+	BuildURL string    `json:"build_url,omitempty"`
+	StoryID  string    `json:"story_id,omitempty"`
+	StoryURL string    `json:"story_url,omitempty"`
+	RedSince time.Time `json:"red_since,omitempty"`
+}
+
+// Status is the groomer's view of its team as of the last poll. Actions lists
+// the most recent events first.
+type Status struct {
+	Team          string      `json:"team"`
+	LastPoll      time.Time   `json:"last_poll,omitempty"`
+	LastPollError string      `json:"last_poll_error,omitempty"`
+	Jobs          []JobStatus `json:"jobs"`
+	Actions       []Event     `json:"actions"`
+}
+
+// Status returns a copy of the state taken at the end of the last poll or
+// pushed build, so it never waits for a poll in progress.
+func (g *Groomer) Status() Status {
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+
+	status := g.status
+	status.Team = g.Team
+	status.Jobs = append([]JobStatus{}, g.status.Jobs...)
+	status.Actions = append([]Event{}, g.actions...)
+	return status
+}
+
+// snapshot records the state shown by Status; callers must hold g.mu.
+func (g *Groomer) snapshot(polled time.Time, err error) {
+	jobs := []JobStatus{}
+	for _, job := range g.latest {
+		build := job.FinishedBuild
+		title := g.storyName(job)
+		if g.inStorm(title) {
+			title = g.Storm.title()
+		}
+		issue := g.open[title]
+		var redSince time.Time
+		if build.Status == "failed" {
+			redSince = g.red[g.storyName(job)].Since
+		}
+		jobs = append(jobs, JobStatus{
+			Pipeline: build.PipelineName,
+			Job:      build.JobName,
+			Group:    g.groupName(job),
+			Status:   build.Status,
+			BuildURL: g.buildURL(job),
+			StoryID:  issue.ID,
+			StoryURL: issue.URL,
+			RedSince: redSince,
+		})
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if greenI, greenJ := jobs[i].RedSince.IsZero(), jobs[j].RedSince.IsZero(); greenI != greenJ {
+			return greenJ
+		}
+		if jobs[i].Pipeline != jobs[j].Pipeline {
+			return jobs[i].Pipeline < jobs[j].Pipeline
+		}
+		return jobs[i].Job < jobs[j].Job
+	})
+
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+	g.status.Jobs = jobs
+	if !polled.IsZero() {
+		g.status.LastPoll = polled
+		g.status.LastPollError = ""
+		if err != nil {
+			g.status.LastPollError = err.Error()
+		}
+	}
+}
+
+func (g *Groomer) recordAction(event Event) {
+	g.statusMu.Lock()
+	defer g.statusMu.Unlock()
+	g.actions = append([]Event{event}, g.actions...)
+	if len(g.actions) > recentActions {
+		g.actions = g.actions[:recentActions]
+	}
+}
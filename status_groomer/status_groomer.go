@@ -2,179 +2,618 @@ package status_groomer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/jaresty/concourse-tracker-bot/tracker"
+	"github.com/jaresty/concourse-tracker-bot/logging"
 )
 
 type Build struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
 	Status       string `json:"status"`
 	JobName      string `json:"job_name"`
 	URL          string `json:"url"`
 	PipelineName string `json:"pipeline_name"`
+	TeamName     string `json:"team_name,omitempty"`
+	StartTime    int64  `json:"start_time,omitempty"`
+	EndTime      int64  `json:"end_time,omitempty"`
 }
 
 type Job struct {
 	FinishedBuild Build `json:"finished_build"`
 }
 
-type TrackerClient interface {
-	Stories(int, string) ([]tracker.Story, error)
-	CreateStory(int, tracker.Story) (tracker.Story, error)
-	ListComments(int, int) ([]tracker.Comment, error)
-	AddComment(int, int, string) error
-}
-
 type ConcourseClient interface {
 	GetJobURLs(string, string) ([]string, error)
+	FailingStep(string, int) (string, error)
 }
 
-type Logger interface {
-	Println(...interface{})
-	Printf(string, ...interface{})
+// PipelineGrouper is implemented by Concourse clients that can tell which
+// pipeline group each "<pipeline>/<job>" is in.
+type PipelineGrouper interface {
+	PipelineGroups(host string, team string) (map[string]string, error)
 }
 
-func updateStory(client TrackerClient, host string, buildURL string, trackerProjectID, storyID int, log Logger) error {
-	commentText := fmt.Sprintf("%s/%s", host, buildURL)
+type Logger = logging.Logger
 
-	comments, err := client.ListComments(trackerProjectID, storyID)
-	for _, c := range comments {
-		if c.Text == commentText {
+type Groomer struct {
+	GroupingStrategy map[string]string
+	Host             string
+	Team             string
+	Backend          IssueBackend
+	Concourse        ConcourseClient
+	Log              Logger
+	Interval         time.Duration
+	ResolveRecovered bool
+	Notifiers        []Notifier
+	PushPollInterval time.Duration
+	PushTimeout      time.Duration
+	History          BuildHistory
+	Flakes           FlakeDetection
+	Escalation       Escalation
+	Storm            StormBreaker
+	Observers        []Observer
+	Audit            Auditor
+	Breakages        BreakageHistory
+	// AutoGroup files jobs under the Concourse group of their pipeline,
+	// "<pipeline>/<group>", unless a rule of the grouping strategy matches
+	// the job or that group name.
+	AutoGroup bool
+	State     StateStore
+	// HTTPClient fetches jobs and builds from Concourse. Without one each
+	// request gives up after 30 seconds.
+	HTTPClient *http.Client
+
+	mu         sync.Mutex
+	open       map[string]trackedIssue
+	resolved   map[string]trackedIssue
+	unfiled    map[string]bool
+	red        map[string]redRun
+	suppressed map[string]suppression
+	flakes     map[string]flakeScore
+	inputs     map[int]string
+	autoGroups map[string]string
+	outbox     []Event
+	storm      *storm
+	cycles     int
+	log        Logger
+	latest     []Job
+	broken     map[string]bool
+	lastFailed map[string]int
+
+	state        State
+	stateLoaded  bool
+	stateChanged bool
+
+	statusMu sync.Mutex
+	status   Status
+	actions  []Event
+}
+
+type trackedIssue struct {
+	Issue
+	Group   string
+	renamed bool
+}
+
+func Groom(groupingStrategy map[string]string, host, team string, trackerProjectID int, client TrackerClient, concourse ConcourseClient, log Logger, maxIterations int) error {
+	g := &Groomer{
+		GroupingStrategy: groupingStrategy,
+		Host:             host,
+		Team:             team,
+		Backend:          TrackerBackend{Client: client, ProjectID: trackerProjectID},
+		Concourse:        concourse,
+		Log:              log,
+	}
+	return g.Run(maxIterations)
+}
+
+func (g *Groomer) Run(maxIterations int) error {
+	interval := g.Interval
+	if interval == 0 {
+		interval = 300 * time.Second
+	}
+
+	var currentIteration int
+	for {
+		err := g.Cycle()
+		if err != nil && classifyError(err) == shutDown {
+			g.Log.Error("the issue tracker rejected the bot's credentials - check the API token and project membership", "error", err)
+			g.lock()
+			g.emit(Event{Type: PollFailed, Error: err.Error()})
+			g.unlock()
+			return err
+		}
+		if err != nil {
+			g.Log.Warn("poll failed, will retry on the next cycle", "error", err)
+			g.lock()
+			g.emit(Event{Type: PollFailed, Error: err.Error()})
+			g.unlock()
+		}
+
+		currentIteration = currentIteration + 1
+		if currentIteration > maxIterations && maxIterations >= 0 {
 			return nil
 		}
+		g.Log.Debug("sleeping", "interval", interval)
+		time.Sleep(interval)
 	}
+}
 
-	log.Println("commenting on previously created story...")
-	err = client.AddComment(trackerProjectID, storyID, commentText)
+func (g *Groomer) Cycle() error {
+	g.lock()
+	defer g.unlock()
+
+	g.cycles++
+	g.log = g.Log.With("cycle_id", g.cycles)
+	stats := CycleStats{Started: time.Now(), Failures: map[Failure]int{}}
+	stats.Err = g.cycle(&stats)
+	stats.Duration = time.Since(stats.Started)
+	stats.CredentialsRejected = stats.Err != nil && classifyError(stats.Err) == shutDown
+	g.snapshot(stats.Started, stats.Err)
+	for _, observer := range g.Observers {
+		observer.CycleFinished(stats)
+	}
+	return stats.Err
+}
+
+func (g *Groomer) cycle(stats *CycleStats) error {
+	g.log.Debug("retrieving jobs")
+	urls, err := g.Concourse.GetJobURLs(g.Host, g.Team)
 	if err != nil {
 		return err
 	}
-	return nil
-}
+	if err := g.LoadAutoGroups(); err != nil {
+		return err
+	}
 
-func createStory(storyName string, log Logger, client TrackerClient, trackerProjectID int, host string, job Job) error {
-	log.Println("creating a new story...")
+	jobs := make([]Job, len(urls))
+	failing := map[string]bool{}
+	for i, url := range urls {
+		g.log.Debug("checking job", "url", url)
 
-	log.Println("retrieving top of backlog story id...")
-	tobStory, err := client.Stories(trackerProjectID, `-type:release state:unstarted`)
-	if err != nil {
+		jobs[i], err = g.fetchJob(url)
+		if err != nil {
+			return err
+		}
+		stats.Jobs++
+		if status := jobs[i].FinishedBuild.Status; status != "succeeded" && status != "" {
+			stats.Failures[Failure{Status: status, Group: g.groupName(jobs[i])}]++
+		}
+		if jobs[i].FinishedBuild.Status == "failed" {
+			title := g.storyName(jobs[i])
+			failing[title] = true
+			g.markRed(title, jobs[i])
+		}
+	}
+
+	g.latest = jobs
+	if err := g.recordBreakages(jobs, failing); err != nil {
 		return err
 	}
-	log.Printf("found story %v\n", tobStory[0].ID)
-
-	story, err := client.CreateStory(trackerProjectID, tracker.Story{
-		Name:         storyName,
-		StoryType:    "chore",
-		CurrentState: "unstarted",
-		Labels: []tracker.Label{
-			{Name: "broken build"},
-		},
-		Comments: []tracker.Comment{
-			{Text: fmt.Sprintf("%s/%s", host, job.FinishedBuild.URL)},
-		},
-		BeforeID: tobStory[0].ID,
-	})
-	if err != nil {
+
+	if err := g.detectStorm(failing); err != nil {
 		return err
 	}
 
-	log.Printf("new story created %v\n", story.ID)
-	return nil
-}
+	passing := map[string]Job{}
+	stormJobs := []Job{}
+	for _, job := range jobs {
+		title := g.storyName(job)
+		switch job.FinishedBuild.Status {
+		case "failed":
+			if g.inStorm(title) {
+				stormJobs = append(stormJobs, job)
+				continue
+			}
+			err := g.handleFailedBuild(title, job)
+			if err != nil && classifyError(err) != skipJob {
+				return err
+			}
+			if err != nil {
+				g.jobLog(job).Warn("skipping job, the issue tracker rejected the story", "error", err)
+				g.ignore(title, Issue{}, job, "the issue tracker rejected the story: "+err.Error())
+			}
+		case "errored", "aborted":
+			g.ignore(title, Issue{}, job, fmt.Sprintf("build %s", job.FinishedBuild.Status))
+		case "succeeded":
+			passing[title] = job
+			if issue, ok := g.open[title]; ok {
+				if score, flaky := g.flakiness(job); flaky {
+					if err := g.markFlaky(title, issue.Issue, job, score); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if err := g.recordStorm(stormJobs); err != nil {
+		return err
+	}
 
-func findExistingStory(storyName string, stories []tracker.Story) *tracker.Story {
-	for _, story := range stories {
-		if story.Name == storyName {
-			return &story
+	for title := range passing {
+		if !failing[title] {
+			delete(g.suppressed, title)
+			delete(g.red, title)
+			if _, ok := g.state.Escalated[title]; ok {
+				delete(g.state.Escalated, title)
+				g.stateChanged = true
+			}
 		}
 	}
+
+	if err := g.escalate(failing); err != nil {
+		return err
+	}
+
+	if g.ResolveRecovered {
+		return g.resolveRecovered(failing, passing)
+	}
 	return nil
 }
 
-func getStoryName(job Job, groupingStrategy map[string]string) string {
-	for regex, groupName := range groupingStrategy {
-		matched, err := regexp.MatchString(regex, fmt.Sprintf("%s-%s", job.FinishedBuild.PipelineName, job.FinishedBuild.JobName))
-		if err == nil && matched {
-			return fmt.Sprintf("%s has failed", groupName)
-		}
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func (g *Groomer) httpClient() *http.Client {
+	if g.HTTPClient != nil {
+		return g.HTTPClient
 	}
-	return fmt.Sprintf("%s/%s has %s", job.FinishedBuild.PipelineName, job.FinishedBuild.JobName, job.FinishedBuild.Status)
+	return defaultHTTPClient
 }
 
-func handleFailedBuild(groupingStrategy map[string]string, job Job, client TrackerClient, host string, trackerProjectID int, log Logger) error {
-	log.Println("build status failed")
-	stories, err := client.Stories(trackerProjectID, `-state:accepted label:"broken build"`)
+func (g *Groomer) fetchJob(url string) (Job, error) {
+	res, err := g.httpClient().Get(url)
 	if err != nil {
-		return err
+		return Job{}, err
+	}
+	defer res.Body.Close()
+
+	job := Job{}
+	if err := json.NewDecoder(res.Body).Decode(&job); err != nil {
+		return Job{}, err
 	}
+	return job, nil
+}
 
-	log.Println("checking for a previously created story...")
-	storyName := getStoryName(job, groupingStrategy)
+func (g *Groomer) handleFailedBuild(title string, job Job) error {
+	g.jobLog(job).Info("build failed")
+	comment := Comment{Link: g.buildURL(job)}
+	g.markRed(title, job)
 
-	existingStory := findExistingStory(storyName, stories)
-	if existingStory != nil {
-		log.Printf("found story %v\n", existingStory.ID)
-		err = updateStory(client, host, job.FinishedBuild.URL, trackerProjectID, existingStory.ID, log)
-		if err != nil {
+	if s, ok := g.suppressedBuild(title, job); ok {
+		g.ignore(title, s.Issue, job, s.Reason)
+		return nil
+	}
+
+	if g.storm != nil {
+		if err := g.joinStorm(title); err != nil {
 			return err
 		}
-		return nil
+	}
+	if g.inStorm(title) {
+		return g.recordStorm([]Job{job})
 	}
 
-	err = createStory(storyName, log, client, trackerProjectID, host, job)
-	if err != nil {
+	group := g.groupName(job)
+	score, flaky := g.flakiness(job)
+	if flaky && g.Flakes.Consolidate {
+		title, group = g.Flakes.title(), ""
+		comment.Text = flakyComment(job, score)
+	}
+
+	issue, err := g.fileBuild(title, group, job, comment)
+	if err != nil || !flaky {
 		return err
 	}
-	return nil
+	return g.markFlaky(title, issue, job, score)
 }
 
-func processURLs(groupingStrategy map[string]string, urls []string, client TrackerClient, host string, trackerProjectID int, log Logger) error {
-	for _, url := range urls {
-		log.Printf("checking %s...\n", url)
+// fileBuild records a failed build on the open story for title, reopening or
+// creating the story as needed.
+func (g *Groomer) fileBuild(title string, group string, job Job, comment Comment) (Issue, error) {
+	log := g.jobLog(job)
+	log.Debug("checking for a previously created story", "title", title)
+	issue, err := g.Backend.FindOpenIssue(title)
+	if err != nil {
+		return Issue{}, err
+	}
 
-		res, err := http.Get(url)
+	if tracked, ok := g.open[title]; issue == nil && ok && tracked.renamed {
+		issue = &tracked.Issue
+	}
+
+	if issue == nil {
+		if previous, ok := g.resolved[title]; ok {
+			log.Info("reopening story", "story_id", previous.ID)
+			err := g.Backend.ReopenIssue(previous.ID)
+			g.audit(AuditEntry{Action: "reopen", StoryID: previous.ID, StoryURL: previous.URL, Title: title}, job, err)
+			if err != nil {
+				return Issue{}, err
+			}
+			delete(g.resolved, title)
+			if g.open == nil {
+				g.open = map[string]trackedIssue{}
+			}
+			g.open[title] = previous
+			g.emit(g.buildEvent(StoryReopened, title, previous.Issue, job))
+			issue = &previous.Issue
+		}
+	}
+
+	if issue != nil {
+		log.Debug("found story", "story_id", issue.ID)
+		g.track(title, *issue, job)
+		added, err := g.updateIssue(log.With("story_id", issue.ID), title, *issue, job, comment)
 		if err != nil {
-			return err
+			return Issue{}, err
 		}
+		if added {
+			g.emit(g.buildEvent(CommentAdded, title, *issue, job))
+		} else {
+			g.ignore(title, *issue, job, "build already recorded on the story")
+		}
+		return *issue, nil
+	}
 
-		job := Job{}
-		if err := json.NewDecoder(res.Body).Decode(&job); err != nil {
-			return err
+	log.Info("creating a new story", "title", title)
+	input := NewIssue{
+		Title:   title,
+		Group:   group,
+		Comment: comment,
+	}
+	created, err := g.Backend.CreateIssue(input)
+	g.audit(AuditEntry{Action: "create", StoryID: created.ID, StoryURL: created.URL, Title: title, Group: group, Payload: input}, job, err)
+	if err != nil {
+		return Issue{}, err
+	}
+
+	log.Info("created story", "story_id", created.ID)
+	g.track(title, created, job)
+	g.emit(g.buildEvent(StoryCreated, title, created, job))
+	return created, nil
+}
+
+func (g *Groomer) updateIssue(log Logger, title string, issue Issue, job Job, comment Comment) (bool, error) {
+	comments, err := g.Backend.Comments(issue.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range comments {
+		if hasLink(c, comment.Link) {
+			return false, nil
 		}
+	}
 
-		if job.FinishedBuild.Status == "failed" {
-			err := handleFailedBuild(groupingStrategy, job, client, host, trackerProjectID, log)
+	log.Info("commenting on story")
+	err = g.Backend.AddComment(issue.ID, comment)
+	g.audit(AuditEntry{Action: "comment", StoryID: issue.ID, StoryURL: issue.URL, Title: title, Payload: comment}, job, err)
+	return true, err
+}
+
+func (g *Groomer) resolveRecovered(failing map[string]bool, passing map[string]Job) error {
+	for title, job := range passing {
+		if failing[title] {
+			continue
+		}
+		issue, ok := g.open[title]
+		if !ok {
+			found, err := g.findUntracked(title, job)
 			if err != nil {
 				return err
 			}
+			if found == nil {
+				continue
+			}
+			issue = *found
+		}
+
+		g.jobLog(job).Info("resolving story", "story_id", issue.ID)
+		err := g.Backend.ResolveIssue(issue.ID)
+		g.audit(AuditEntry{Action: "resolve", StoryID: issue.ID, StoryURL: issue.URL, Title: title}, job, err)
+		if err != nil {
+			return err
+		}
+		delete(g.open, title)
+		if g.resolved == nil {
+			g.resolved = map[string]trackedIssue{}
 		}
+		g.resolved[title] = issue
+		g.emit(g.buildEvent(StoryResolved, title, issue.Issue, job))
 	}
 	return nil
 }
 
-func Groom(groupingStrategy map[string]string, host, team string, trackerProjectID int, client TrackerClient, concourse ConcourseClient, log Logger, maxIterations int) {
-	var currentIteration int
-	for {
-		log.Println("retrieving jobs...")
-		urls, err := concourse.GetJobURLs(host, team)
-		if err != nil {
-			log.Println(err)
+// findUntracked looks up the open story of a group that is not in g.open, as
+// after a restart or in a single pass, once per group.
+func (g *Groomer) findUntracked(title string, job Job) (*trackedIssue, error) {
+	if g.unfiled[title] {
+		return nil, nil
+	}
+	issue, err := g.Backend.FindOpenIssue(title)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		if g.unfiled == nil {
+			g.unfiled = map[string]bool{}
 		}
+		g.unfiled[title] = true
+		return nil, nil
+	}
+	return &trackedIssue{Issue: *issue, Group: g.groupName(job)}, nil
+}
 
-		log.Println("checking for build errors...")
-		err = processURLs(groupingStrategy, urls, client, host, trackerProjectID, log)
-		if err != nil {
-			log.Println(err)
-		}
+// redRun is a run of failures of the jobs filed under a story title. It is
+// the one record of how long a group has been red, read by escalation, storm
+// detection and the dashboard alike.
+type redRun struct {
+	Job   Job
+	Since time.Time
+}
 
-		currentIteration = currentIteration + 1
-		if currentIteration > maxIterations && maxIterations >= 0 {
-			break
+// markRed records the latest failed build of a group, starting a run of
+// failures when the group was green.
+func (g *Groomer) markRed(title string, job Job) {
+	if g.red == nil {
+		g.red = map[string]redRun{}
+	}
+	run, ok := g.red[title]
+	if !ok {
+		run.Since = g.failingSince(job)
+	}
+	run.Job = job
+	g.red[title] = run
+}
+
+func (g *Groomer) track(title string, issue Issue, job Job) {
+	if g.open == nil {
+		g.open = map[string]trackedIssue{}
+	}
+	delete(g.unfiled, title)
+	tracked := trackedIssue{Issue: issue, Group: g.groupName(job)}
+	if previous, ok := g.open[title]; ok && previous.ID == issue.ID {
+		tracked.renamed = previous.renamed
+	}
+	g.open[title] = tracked
+}
+
+// jobLog returns the logger of the current cycle with the fields of a job's
+// latest build.
+func (g *Groomer) jobLog(job Job) Logger {
+	return g.log.With(
+		"pipeline", job.FinishedBuild.PipelineName,
+		"job", job.FinishedBuild.JobName,
+		"build_id", job.FinishedBuild.ID,
+		"group", g.groupName(job),
+	)
+}
+
+func (g *Groomer) buildURL(job Job) string {
+	return fmt.Sprintf("%s/%s", g.Host, job.FinishedBuild.URL)
+}
+
+func (g *Groomer) groupName(job Job) string {
+	_, group := g.groupRule(job)
+	return group
+}
+
+func (g *Groomer) storyName(job Job) string {
+	if group := g.groupName(job); group != "" {
+		return fmt.Sprintf("%s has failed", group)
+	}
+	return fmt.Sprintf("%s/%s has failed", job.FinishedBuild.PipelineName, job.FinishedBuild.JobName)
+}
+
+type errorAction int
+
+const (
+	skipJob errorAction = iota
+	retryCycle
+	shutDown
+)
+
+// backendError is implemented by the typed errors of each issue tracker
+// client so that the groomer can react to them without knowing which
+// tracker it is talking to.
+type backendError interface {
+	error
+	Unauthorized() bool
+	Forbidden() bool
+	Temporary() bool
+}
+
+func classifyError(err error) errorAction {
+	var backendErr backendError
+	if !errors.As(err, &backendErr) {
+		return retryCycle
+	}
+
+	switch {
+	case backendErr.Unauthorized(), backendErr.Forbidden():
+		return shutDown
+	case backendErr.Temporary():
+		return retryCycle
+	default:
+		return skipJob
+	}
+}
+
+// LoadAutoGroups fetches the Concourse group of every job when auto grouping.
+func (g *Groomer) LoadAutoGroups() error {
+	grouper, ok := g.Concourse.(PipelineGrouper)
+	if !ok || !g.AutoGroup {
+		return nil
+	}
+	groups, err := grouper.PipelineGroups(g.Host, g.Team)
+	if err != nil {
+		return err
+	}
+	g.autoGroups = groups
+	return nil
+}
+
+// SetGroupingStrategy swaps the grouping rules. A poll in progress finishes
+// with the previous rules.
+func (g *Groomer) SetGroupingStrategy(strategy map[string]string) {
+	g.lock()
+	defer g.unlock()
+	g.GroupingStrategy = strategy
+}
+
+// groupRule returns the pattern of the grouping strategy a job matches and
+// its group, or "concourse" and the job's pipeline group when auto grouping
+// and no pattern matches.
+func (g *Groomer) groupRule(job Job) (string, string) {
+	build := job.FinishedBuild
+	auto := g.AutoGroupOf(build.PipelineName, build.JobName)
+	regexes := make([]string, 0, len(g.GroupingStrategy))
+	for regex := range g.GroupingStrategy {
+		regexes = append(regexes, regex)
+	}
+	// rules are tried by group and pattern so that a job matching rules of
+	// several groups is always filed under the same one
+	sort.Slice(regexes, func(i, j int) bool {
+		a, b := regexes[i], regexes[j]
+		if g.GroupingStrategy[a] != g.GroupingStrategy[b] {
+			return g.GroupingStrategy[a] < g.GroupingStrategy[b]
+		}
+		return a < b
+	})
+	for _, regex := range regexes {
+		groupName := g.GroupingStrategy[regex]
+		matched, err := regexp.MatchString(regex, fmt.Sprintf("%s-%s", build.PipelineName, build.JobName))
+		if err == nil && !matched && auto != "" {
+			matched, err = regexp.MatchString(regex, auto)
+		}
+		if err == nil && matched {
+			return regex, groupName
 		}
-		log.Println("sleeping...")
-		time.Sleep(300 * time.Second)
 	}
+	if auto != "" {
+		return "concourse", auto
+	}
+	return "", ""
+}
+
+// AutoGroupOf returns the Concourse group of a job loaded by LoadAutoGroups.
+func (g *Groomer) AutoGroupOf(pipeline, job string) string {
+	return g.autoGroups[pipeline+"/"+job]
+}
+
+// GroupRule returns the pattern and group the groomer files failures of a job
+// under, see groupRule.
+func (g *Groomer) GroupRule(pipeline, job string) (string, string) {
+	return g.groupRule(Job{FinishedBuild: Build{PipelineName: pipeline, JobName: job}})
+}
+
+// Title returns the story name the groomer files failures of a job under.
+func (g *Groomer) Title(pipeline, job string) string {
+	return g.storyName(Job{FinishedBuild: Build{PipelineName: pipeline, JobName: job}})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/jaresty/concourse-tracker-bot/logging"
 	. "github.com/jaresty/concourse-tracker-bot/status_groomer"
 	"github.com/jaresty/concourse-tracker-bot/status_groomer/fakes"
 	"github.com/jaresty/concourse-tracker-bot/tracker"
@@ -23,7 +24,7 @@ var _ = Describe("StatusGroomer", func() {
 		mockServer          *ghttp.Server
 		mockTrackerClient   *fakes.FakeTrackerClient
 		mockConcourseClient *fakes.FakeConcourseClient
-		mockLog             *fakes.FakeLogger
+		mockLog             logging.Recorder
 		groupingStrategy    map[string]string
 	)
 
@@ -32,7 +33,7 @@ var _ = Describe("StatusGroomer", func() {
 			mockServer = ghttp.NewServer()
 			mockTrackerClient = new(fakes.FakeTrackerClient)
 			mockConcourseClient = new(fakes.FakeConcourseClient)
-			mockLog = new(fakes.FakeLogger)
+			mockLog = logging.NewRecorder()
 			groupingStrategy = make(map[string]string)
 			groupingStrategy["(fooPipeline-.*-groupa)"] = "groupa"
 
@@ -197,6 +198,71 @@ var _ = Describe("StatusGroomer", func() {
 				})
 			})
 		})
+
+		Context("when Tracker returns an error", func() {
+			BeforeEach(func() {
+				mockConcourseClient.GetJobURLsReturns([]string{
+					mockServerUrl + "/failed/group/1",
+					mockServerUrl + "/failed/nogroup/1",
+				}, nil)
+				mockServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/failed/group/1"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, failedJob),
+					))
+				mockServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/failed/nogroup/1"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, failedJob3),
+					))
+				mockTrackerClient.StoriesReturns([]tracker.Story{tracker.Story{ID: 2}}, nil)
+			})
+
+			Context("when the API token is rejected", func() {
+				BeforeEach(func() {
+					mockTrackerClient.CreateStoryReturns(tracker.Story{}, &tracker.Error{
+						StatusCode: http.StatusUnauthorized,
+						Code:       "invalid_authentication",
+					})
+				})
+
+				It("stops grooming and returns the error", func() {
+					err := Groom(groupingStrategy, mockServerUrl, "husbandandwife", 12345, mockTrackerClient, mockConcourseClient, mockLog, 5)
+					Expect(err).To(HaveOccurred())
+					Expect(mockTrackerClient.CreateStoryCallCount()).To(Equal(1))
+					Expect(mockConcourseClient.GetJobURLsCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the story is rejected", func() {
+				BeforeEach(func() {
+					mockTrackerClient.CreateStoryReturns(tracker.Story{}, &tracker.Error{
+						StatusCode: http.StatusBadRequest,
+						Code:       "invalid_parameter",
+					})
+				})
+
+				It("skips the job and moves on to the next one", func() {
+					err := Groom(groupingStrategy, mockServerUrl, "husbandandwife", 12345, mockTrackerClient, mockConcourseClient, mockLog, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mockTrackerClient.CreateStoryCallCount()).To(Equal(2))
+				})
+			})
+
+			Context("when Tracker is unavailable", func() {
+				BeforeEach(func() {
+					mockTrackerClient.CreateStoryReturns(tracker.Story{}, &tracker.Error{
+						StatusCode: http.StatusServiceUnavailable,
+					})
+				})
+
+				It("abandons the rest of the cycle so it can be retried", func() {
+					err := Groom(groupingStrategy, mockServerUrl, "husbandandwife", 12345, mockTrackerClient, mockConcourseClient, mockLog, 0)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mockTrackerClient.CreateStoryCallCount()).To(Equal(1))
+				})
+			})
+		})
 	})
 
 })
@@ -0,0 +1,187 @@
+package status_groomer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StormBreaker files a single story instead of one per group when many groups
+// start failing at once, e.g. during an IaaS outage.
+type StormBreaker struct {
+	// Groups is the number of groups that may start failing within Window
+	// before it is treated as a storm. Zero disables the breaker.
+	Groups int      `yaml:"groups"`
+	Window Duration `yaml:"window"`
+	Title  string   `yaml:"title"`
+}
+
+func (s StormBreaker) title() string {
+	if s.Title == "" {
+		return "widespread failure"
+	}
+	return s.Title
+}
+
+func (s StormBreaker) window() time.Duration {
+	if s.Window == 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(s.Window)
+}
+
+type storm struct {
+	titles   map[string]bool
+	filed    map[string]bool
+	recorded map[string]bool
+}
+
+// detectStorm starts a storm when more than the configured number of groups
+// without a story started failing within the window, and clears it once no
+// more than that number of its groups are still failing. Groups without a
+// story that start failing during the storm join it.
+func (g *Groomer) detectStorm(failing map[string]bool) error {
+	if g.Storm.Groups == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if g.storm != nil {
+		for title := range failing {
+			if err := g.joinStorm(title); err != nil {
+				return err
+			}
+		}
+		stillFailing := 0
+		for title := range g.storm.titles {
+			if failing[title] {
+				stillFailing++
+			}
+		}
+		if stillFailing > g.Storm.Groups {
+			return nil
+		}
+		return g.clearStorm(stillFailing)
+	}
+
+	candidates := []string{}
+	for title := range failing {
+		_, filed := g.open[title]
+		if !filed && now.Sub(g.red[title].Since) <= g.Storm.window() {
+			candidates = append(candidates, title)
+		}
+	}
+	if len(candidates) <= g.Storm.Groups {
+		return nil
+	}
+
+	// stories filed before a restart are not in g.open yet
+	titles := map[string]bool{}
+	for _, title := range candidates {
+		issue, err := g.Backend.FindOpenIssue(title)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			titles[title] = true
+		}
+	}
+	if len(titles) <= g.Storm.Groups {
+		return nil
+	}
+
+	g.log.Warn("groups started failing together, filing them on a single story", "groups", len(titles), "window", g.Storm.window())
+	g.storm = &storm{titles: titles, filed: map[string]bool{}, recorded: map[string]bool{}}
+	g.emit(Event{Type: StormStarted, Title: g.Storm.title(), Reason: fmt.Sprintf("%d groups started failing within %s", len(titles), g.Storm.window())})
+	return nil
+}
+
+// joinStorm adds a failing group to the storm unless it already has a story,
+// looking each group up in the issue tracker once.
+func (g *Groomer) joinStorm(title string) error {
+	if g.storm.titles[title] || g.storm.filed[title] {
+		return nil
+	}
+	if _, filed := g.open[title]; !filed {
+		issue, err := g.Backend.FindOpenIssue(title)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			g.log.Info("group started failing during the widespread failure, filing it on the storm story", "title", title)
+			g.storm.titles[title] = true
+			return nil
+		}
+	}
+	g.storm.filed[title] = true
+	return nil
+}
+
+func (g *Groomer) inStorm(title string) bool {
+	return g.storm != nil && g.storm.titles[title]
+}
+
+// recordStorm lists the failed builds not yet recorded on the storm story in
+// a single comment, creating the story if needed.
+func (g *Groomer) recordStorm(jobs []Job) error {
+	lines := []string{}
+	links := []string{}
+	for _, job := range jobs {
+		link := g.buildURL(job)
+		if g.storm.recorded[link] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s/%s %s", strings.TrimSuffix(g.storyName(job), " has failed"), job.FinishedBuild.PipelineName, job.FinishedBuild.JobName, link))
+		links = append(links, link)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sort.Strings(lines)
+
+	comment := Comment{
+		Text: fmt.Sprintf("%d jobs failed during a widespread failure:\n%s\nFirst build:", len(lines), strings.Join(lines, "\n")),
+		Link: links[0],
+	}
+	if _, err := g.fileBuild(g.Storm.title(), "", jobs[0], comment); err != nil {
+		return err
+	}
+	for _, link := range links {
+		g.storm.recorded[link] = true
+	}
+	return nil
+}
+
+func (g *Groomer) clearStorm(stillFailing int) error {
+	g.log.Info("the widespread failure has cleared", "still_failing", stillFailing)
+	title := g.Storm.title()
+	issue, tracked := g.open[title]
+	g.storm = nil
+
+	if !tracked {
+		g.emit(Event{Type: StormCleared, Title: title})
+		return nil
+	}
+
+	text := "The widespread failure has cleared."
+	if stillFailing > 0 {
+		text += " Groups that are still failing get stories of their own."
+	}
+	comment := Comment{Text: text}
+	err := g.Backend.AddComment(issue.ID, comment)
+	g.audit(AuditEntry{Action: "comment", StoryID: issue.ID, StoryURL: issue.URL, Title: title, Payload: comment}, Job{}, err)
+	if err != nil {
+		return err
+	}
+	if g.ResolveRecovered {
+		err := g.Backend.ResolveIssue(issue.ID)
+		g.audit(AuditEntry{Action: "resolve", StoryID: issue.ID, StoryURL: issue.URL, Title: title}, Job{}, err)
+		if err != nil {
+			return err
+		}
+		delete(g.open, title)
+	}
+	g.emit(Event{Type: StormCleared, Title: title, IssueID: issue.ID, IssueURL: issue.URL})
+	return nil
+}
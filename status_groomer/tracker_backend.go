@@ -0,0 +1,174 @@
+package status_groomer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+)
+
+type TrackerClient interface {
+	Stories(int, string) ([]tracker.Story, error)
+	CreateStory(int, tracker.Story) (tracker.Story, error)
+	UpdateStory(int, int, tracker.Story) (tracker.Story, error)
+	ListComments(int, int) ([]tracker.Comment, error)
+	AddComment(int, int, string) error
+	AddLabel(int, int, string) error
+}
+
+type TrackerBackend struct {
+	Client    TrackerClient
+	ProjectID int
+}
+
+func (t TrackerBackend) FindOpenIssue(title string) (*Issue, error) {
+	stories, err := t.Client.Stories(t.ProjectID, `-state:accepted label:"broken build"`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, story := range stories {
+		if story.Name == title {
+			issue := storyToIssue(story)
+			return &issue, nil
+		}
+	}
+	return nil, nil
+}
+
+func (t TrackerBackend) CreateIssue(input NewIssue) (Issue, error) {
+	story := tracker.Story{
+		Name:         input.Title,
+		StoryType:    "chore",
+		CurrentState: "unstarted",
+		Labels: []tracker.Label{
+			{Name: "broken build"},
+		},
+		Comments: []tracker.Comment{
+			{Text: renderTrackerComment(input.Comment)},
+		},
+	}
+
+	tobStory, err := t.Client.Stories(t.ProjectID, `-type:release state:unstarted`)
+	if err != nil {
+		return Issue{}, err
+	}
+	if len(tobStory) > 0 {
+		story.BeforeID = tobStory[0].ID
+	}
+
+	created, err := t.Client.CreateStory(t.ProjectID, story)
+	if err != nil {
+		return Issue{}, err
+	}
+	return storyToIssue(created), nil
+}
+
+func (t TrackerBackend) FileReport(title string, comment Comment) (Issue, error) {
+	created, err := t.Client.CreateStory(t.ProjectID, tracker.Story{
+		Name:         title,
+		StoryType:    "chore",
+		CurrentState: "unscheduled",
+		Labels:       []tracker.Label{{Name: "build health"}},
+		Comments:     []tracker.Comment{{Text: renderTrackerComment(comment)}},
+	})
+	if err != nil {
+		return Issue{}, err
+	}
+	return storyToIssue(created), nil
+}
+
+func (t TrackerBackend) Comments(issueID string) ([]string, error) {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := t.Client.ListComments(t.ProjectID, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(comments))
+	for i, c := range comments {
+		texts[i] = c.Text
+	}
+	return texts, nil
+}
+
+func (t TrackerBackend) AddComment(issueID string, comment Comment) error {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return err
+	}
+	return t.Client.AddComment(t.ProjectID, storyID, renderTrackerComment(comment))
+}
+
+func (t TrackerBackend) AddLabel(issueID string, label string) error {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return err
+	}
+	return t.Client.AddLabel(t.ProjectID, storyID, label)
+}
+
+func (t TrackerBackend) MoveToTop(issueID string) error {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return err
+	}
+
+	tobStory, err := t.Client.Stories(t.ProjectID, `-type:release state:unstarted`)
+	if err != nil {
+		return err
+	}
+	if len(tobStory) == 0 || tobStory[0].ID == storyID {
+		return nil
+	}
+	_, err = t.Client.UpdateStory(t.ProjectID, storyID, tracker.Story{BeforeID: tobStory[0].ID})
+	return err
+}
+
+func (t TrackerBackend) SetType(issueID string, storyType string) error {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return err
+	}
+	_, err = t.Client.UpdateStory(t.ProjectID, storyID, tracker.Story{StoryType: storyType})
+	return err
+}
+
+func (t TrackerBackend) ResolveIssue(issueID string) error {
+	return t.setState(issueID, "accepted")
+}
+
+func (t TrackerBackend) ReopenIssue(issueID string) error {
+	return t.setState(issueID, "unstarted")
+}
+
+func (t TrackerBackend) setState(issueID string, state string) error {
+	storyID, err := strconv.Atoi(issueID)
+	if err != nil {
+		return err
+	}
+	_, err = t.Client.UpdateStory(t.ProjectID, storyID, tracker.Story{CurrentState: state})
+	return err
+}
+
+func storyToIssue(story tracker.Story) Issue {
+	return Issue{
+		ID:    strconv.Itoa(story.ID),
+		Title: story.Name,
+		URL:   story.URL,
+	}
+}
+
+func renderTrackerComment(comment Comment) string {
+	if comment.Text == "" {
+		return comment.Link
+	}
+	if comment.Link == "" {
+		return comment.Text
+	}
+	return fmt.Sprintf("%s %s", comment.Text, comment.Link)
+}
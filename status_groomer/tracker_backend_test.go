@@ -0,0 +1,119 @@
+package status_groomer_test
+
+import (
+	. "github.com/jaresty/concourse-tracker-bot/status_groomer"
+	"github.com/jaresty/concourse-tracker-bot/status_groomer/fakes"
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrackerBackend", func() {
+	var (
+		mockTrackerClient *fakes.FakeTrackerClient
+		backend           TrackerBackend
+	)
+
+	BeforeEach(func() {
+		mockTrackerClient = new(fakes.FakeTrackerClient)
+		backend = TrackerBackend{Client: mockTrackerClient, ProjectID: 12345}
+	})
+
+	It("finds open broken build stories by name", func() {
+		mockTrackerClient.StoriesReturns([]tracker.Story{
+			{ID: 1, Name: "groupb has failed"},
+			{ID: 2, Name: "groupa has failed", URL: "https://www.pivotaltracker.com/story/show/2"},
+		}, nil)
+
+		issue, err := backend.FindOpenIssue("groupa has failed")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue).To(Equal(&Issue{ID: "2", Title: "groupa has failed", URL: "https://www.pivotaltracker.com/story/show/2"}))
+
+		_, filter := mockTrackerClient.StoriesArgsForCall(0)
+		Expect(filter).To(Equal(`-state:accepted label:"broken build"`))
+	})
+
+	It("creates the story without a position when the backlog is empty", func() {
+		mockTrackerClient.StoriesReturns([]tracker.Story{}, nil)
+		mockTrackerClient.CreateStoryReturns(tracker.Story{ID: 3, Name: "groupa has failed"}, nil)
+
+		issue, err := backend.CreateIssue(NewIssue{Title: "groupa has failed", Comment: Comment{Link: "https://ci/builds/1"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.ID).To(Equal("3"))
+
+		_, story := mockTrackerClient.CreateStoryArgsForCall(0)
+		Expect(story.BeforeID).To(Equal(0))
+		Expect(story.Comments).To(Equal([]tracker.Comment{{Text: "https://ci/builds/1"}}))
+	})
+
+	It("files reports in the icebox without the broken build label", func() {
+		mockTrackerClient.CreateStoryReturns(tracker.Story{ID: 6}, nil)
+		issue, err := backend.FileReport("Build health report for 2026-10-19", Comment{Text: "| Group |"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(issue.ID).To(Equal("6"))
+
+		Expect(mockTrackerClient.StoriesCallCount()).To(Equal(0))
+		_, story := mockTrackerClient.CreateStoryArgsForCall(0)
+		Expect(story).To(Equal(tracker.Story{
+			Name:         "Build health report for 2026-10-19",
+			StoryType:    "chore",
+			CurrentState: "unscheduled",
+			Labels:       []tracker.Label{{Name: "build health"}},
+			Comments:     []tracker.Comment{{Text: "| Group |"}},
+		}))
+	})
+
+	It("renders comment text ahead of the link", func() {
+		Expect(backend.AddComment("4", Comment{Text: "still failing", Link: "https://ci/builds/2"})).To(Succeed())
+
+		projectID, storyID, text := mockTrackerClient.AddCommentArgsForCall(0)
+		Expect(projectID).To(Equal(12345))
+		Expect(storyID).To(Equal(4))
+		Expect(text).To(Equal("still failing https://ci/builds/2"))
+	})
+
+	It("accepts stories to resolve them and restarts them to reopen them", func() {
+		Expect(backend.ResolveIssue("5")).To(Succeed())
+		_, storyID, update := mockTrackerClient.UpdateStoryArgsForCall(0)
+		Expect(storyID).To(Equal(5))
+		Expect(update).To(Equal(tracker.Story{CurrentState: "accepted"}))
+
+		Expect(backend.ReopenIssue("5")).To(Succeed())
+		_, _, update = mockTrackerClient.UpdateStoryArgsForCall(1)
+		Expect(update).To(Equal(tracker.Story{CurrentState: "unstarted"}))
+	})
+
+	It("labels stories", func() {
+		Expect(backend.AddLabel("5", "flaky")).To(Succeed())
+		projectID, storyID, label := mockTrackerClient.AddLabelArgsForCall(0)
+		Expect(projectID).To(Equal(12345))
+		Expect(storyID).To(Equal(5))
+		Expect(label).To(Equal("flaky"))
+	})
+
+	It("moves stories ahead of the top of the backlog", func() {
+		mockTrackerClient.StoriesReturns([]tracker.Story{{ID: 1}, {ID: 5}}, nil)
+		Expect(backend.MoveToTop("5")).To(Succeed())
+
+		_, storyID, update := mockTrackerClient.UpdateStoryArgsForCall(0)
+		Expect(storyID).To(Equal(5))
+		Expect(update).To(Equal(tracker.Story{BeforeID: 1}))
+	})
+
+	It("leaves stories already at the top of the backlog", func() {
+		mockTrackerClient.StoriesReturns([]tracker.Story{{ID: 5}}, nil)
+		Expect(backend.MoveToTop("5")).To(Succeed())
+		Expect(mockTrackerClient.UpdateStoryCallCount()).To(Equal(0))
+	})
+
+	It("changes the story type", func() {
+		Expect(backend.SetType("5", "bug")).To(Succeed())
+		_, _, update := mockTrackerClient.UpdateStoryArgsForCall(0)
+		Expect(update).To(Equal(tracker.Story{StoryType: "bug"}))
+	})
+
+	It("rejects issue IDs that are not story IDs", func() {
+		Expect(backend.AddComment("abc", Comment{})).To(MatchError(ContainSubstring("invalid syntax")))
+	})
+})
@@ -0,0 +1,126 @@
+package status_groomer
+
+// suppression keeps the groomer from filing a title again after a human acted
+// on its story. Nil Builds suppresses every build until the title passes
+// again.
+type suppression struct {
+	Issue  Issue
+	Builds map[string]bool
+	Reason string
+}
+
+// StoryAccepted is called when a human accepts one of the bot's stories. If
+// the build is still red the story is told so, and only a new failure of any
+// of its jobs will reopen it.
+func (g *Groomer) StoryAccepted(issueID string) error {
+	g.lock()
+	defer g.unlock()
+	g.log = g.Log
+
+	title, issue, ok := g.trackedByID(issueID)
+	if !ok {
+		return nil
+	}
+	delete(g.open, title)
+	if g.resolved == nil {
+		g.resolved = map[string]trackedIssue{}
+	}
+	g.resolved[title] = issue
+
+	run, red := g.red[title]
+	if !red {
+		return nil
+	}
+	job := run.Job
+
+	g.jobLog(job).Info("story was accepted while the build is still failing", "story_id", issueID)
+	g.suppress(title, suppression{Issue: issue.Issue, Builds: g.redBuilds(title, job), Reason: "story accepted while the build was still failing"})
+	comment := Comment{
+		Text: "This story was accepted but the build is still failing:",
+		Link: g.buildURL(job),
+	}
+	err := g.Backend.AddComment(issueID, comment)
+	g.audit(AuditEntry{Action: "comment", StoryID: issueID, StoryURL: issue.URL, Title: title, Payload: comment}, job, err)
+	return err
+}
+
+// StoryDeleted is called when a human deletes one of the bot's stories. The
+// title is left alone until its jobs pass again rather than being filed anew
+// on every poll.
+func (g *Groomer) StoryDeleted(issueID string) error {
+	g.lock()
+	defer g.unlock()
+	g.log = g.Log
+
+	title, issue, ok := g.trackedByID(issueID)
+	if !ok {
+		return nil
+	}
+	g.log.Info("story was deleted, ignoring it until it passes", "story_id", issueID, "title", title)
+	delete(g.open, title)
+	delete(g.resolved, title)
+	g.suppress(title, suppression{Issue: issue.Issue, Reason: "story deleted until the build passes"})
+	return nil
+}
+
+// StoryRenamed is called when a human renames one of the bot's stories, which
+// would otherwise no longer be found by title.
+func (g *Groomer) StoryRenamed(issueID string, name string) error {
+	g.lock()
+	defer g.unlock()
+	g.log = g.Log
+
+	title, issue, ok := g.trackedByID(issueID)
+	if !ok {
+		return nil
+	}
+	issue.Title = name
+	issue.renamed = true
+	if _, open := g.open[title]; open {
+		g.open[title] = issue
+	} else {
+		g.resolved[title] = issue
+	}
+	return nil
+}
+
+func (g *Groomer) trackedByID(issueID string) (string, trackedIssue, bool) {
+	for title, issue := range g.open {
+		if issue.ID == issueID {
+			return title, issue, true
+		}
+	}
+	for title, issue := range g.resolved {
+		if issue.ID == issueID {
+			return title, issue, true
+		}
+	}
+	return "", trackedIssue{}, false
+}
+
+func (g *Groomer) suppress(title string, s suppression) {
+	if g.suppressed == nil {
+		g.suppressed = map[string]suppression{}
+	}
+	g.suppressed[title] = s
+}
+
+func (g *Groomer) suppressedBuild(title string, job Job) (suppression, bool) {
+	s, ok := g.suppressed[title]
+	if !ok || (s.Builds != nil && !s.Builds[g.buildURL(job)]) {
+		return suppression{}, false
+	}
+	return s, true
+}
+
+// redBuilds returns the failed builds of every job filed under title as of the
+// last poll, along with job.
+func (g *Groomer) redBuilds(title string, job Job) map[string]bool {
+	builds := map[string]bool{g.buildURL(job): true}
+	for _, latest := range g.latest {
+		if latest.FinishedBuild.Status == "failed" && g.storyName(latest) == title {
+			builds[g.buildURL(latest)] = true
+		}
+	}
+	return builds
+}
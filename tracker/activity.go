@@ -0,0 +1,31 @@
+package tracker
+
+// Activity is the payload of a Tracker activity webhook.
+type Activity struct {
+	Kind             string     `json:"kind"`
+	Highlight        string     `json:"highlight"`
+	Changes          []Change   `json:"changes"`
+	PrimaryResources []Resource `json:"primary_resources"`
+	Project          Resource   `json:"project"`
+}
+
+type Change struct {
+	Kind           string      `json:"kind"`
+	ChangeType     string      `json:"change_type"`
+	ID             int         `json:"id"`
+	Name           string      `json:"name"`
+	OriginalValues StoryValues `json:"original_values"`
+	NewValues      StoryValues `json:"new_values"`
+}
+
+type StoryValues struct {
+	Name         string `json:"name"`
+	CurrentState string `json:"current_state"`
+}
+
+type Resource struct {
+	Kind string `json:"kind"`
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
@@ -4,18 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 type Client struct {
 	TrackerAPI string
 	APIToken   string
+	PageSize   int
+	HTTPClient *http.Client
 }
 
 type Story struct {
-	Name         string    `json:"name"`
+	Name         string    `json:"name,omitempty"`
 	ID           int       `json:"id,omitempty"`
+	URL          string    `json:"url,omitempty"`
 	CurrentState string    `json:"current_state,omitempty"`
 	Labels       []Label   `json:"labels,omitempty"`
 	StoryType    string    `json:"story_type,omitempty"`
@@ -33,46 +36,66 @@ type Label struct {
 
 func (c Client) doRequest(req *http.Request) (*http.Response, error) {
 	req.Header.Add("X-TrackerToken", c.APIToken)
+	if c.HTTPClient != nil {
+		return c.HTTPClient.Do(req)
+	}
 	return http.DefaultClient.Do(req)
 }
 
 func (c Client) Stories(projectID int, filter string) ([]Story, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/projects/%d/stories", c.TrackerAPI, projectID), nil)
-	if err != nil {
+	stories := []Story{}
+	it := c.StoryIterator(projectID, filter)
+	for it.Next() {
+		stories = append(stories, it.Story())
+	}
+	if err := it.Err(); err != nil {
 		return []Story{}, err
 	}
 
-	if filter != "" {
-		q := req.URL.Query()
-		q.Set("filter", filter)
-		req.URL.RawQuery = q.Encode()
+	return stories, nil
+}
+
+func (c Client) CreateStory(projectID int, input Story) (Story, error) {
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(input); err != nil {
+		return Story{}, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/projects/%d/stories", c.TrackerAPI, projectID), body)
+	if err != nil {
+		return Story{}, err
 	}
 
+	req.Header.Set("Content-Type", "application/json")
+	req = WithExistenceCheck(req, func() ([]byte, bool, error) {
+		return c.findCreatedStory(projectID, input.Name)
+	})
+
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return []Story{}, err
+		return Story{}, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return []Story{}, fmt.Errorf("%s - %s", resp.Status, string(body))
+		return Story{}, newError(resp)
 	}
 
-	var stories []Story
-	if err := json.NewDecoder(resp.Body).Decode(&stories); err != nil {
-		return []Story{}, err
+	var story Story
+	if err := json.NewDecoder(resp.Body).Decode(&story); err != nil {
+		return Story{}, err
 	}
 
-	return stories, nil
+	return story, nil
 }
 
-func (c Client) CreateStory(projectID int, input Story) (Story, error) {
+func (c Client) UpdateStory(projectID int, storyID int, input Story) (Story, error) {
 	body := &bytes.Buffer{}
 	if err := json.NewEncoder(body).Encode(input); err != nil {
 		return Story{}, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/projects/%d/stories", c.TrackerAPI, projectID), body)
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/projects/%d/stories/%d", c.TrackerAPI, projectID, storyID), body)
 	if err != nil {
 		return Story{}, err
 	}
@@ -83,10 +106,10 @@ func (c Client) CreateStory(projectID int, input Story) (Story, error) {
 	if err != nil {
 		return Story{}, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return Story{}, fmt.Errorf("%s - %s", resp.Status, string(body))
+		return Story{}, newError(resp)
 	}
 
 	var story Story
@@ -109,40 +132,124 @@ func (c Client) AddComment(projectID int, storyID int, comment string) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req = WithExistenceCheck(req, func() ([]byte, bool, error) {
+		return c.findAddedComment(projectID, storyID, comment)
+	})
 
 	resp, err := c.doRequest(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("%s - %s", resp.Status, string(body))
+		return newError(resp)
 	}
 
 	return nil
 }
 
-func (c Client) ListComments(projectID int, storyID int) ([]Comment, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/projects/%d/stories/%d/comments", c.TrackerAPI, projectID, storyID), nil)
+func (c Client) AddLabel(projectID int, storyID int, label string) error {
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(Label{Name: label}); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/projects/%d/stories/%d/labels", c.TrackerAPI, projectID, storyID), body)
 	if err != nil {
-		return []Comment{}, err
+		return err
 	}
 
+	req.Header.Set("Content-Type", "application/json")
+	req = WithExistenceCheck(req, func() ([]byte, bool, error) {
+		return c.findAddedLabel(projectID, storyID, label)
+	})
+
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return []Comment{}, err
+		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return []Comment{}, fmt.Errorf("%s - %s", resp.Status, string(body))
+		return newError(resp)
 	}
 
-	var comments []Comment
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+	return nil
+}
+
+func (c Client) ListComments(projectID int, storyID int) ([]Comment, error) {
+	comments := []Comment{}
+	it := c.CommentIterator(projectID, storyID)
+	for it.Next() {
+		comments = append(comments, it.Comment())
+	}
+	if err := it.Err(); err != nil {
 		return []Comment{}, err
 	}
 
 	return comments, nil
 }
+
+// searchQuote escapes a value for a quoted term of a Tracker search.
+var searchQuote = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (c Client) findCreatedStory(projectID int, name string) ([]byte, bool, error) {
+	stories, err := c.Stories(projectID, fmt.Sprintf(`name:"%s" -state:accepted`, searchQuote.Replace(name)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, story := range stories {
+		if story.Name == name {
+			body, err := json.Marshal(story)
+			return body, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (c Client) findAddedComment(projectID int, storyID int, text string) ([]byte, bool, error) {
+	comments, err := c.ListComments(projectID, storyID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, comment := range comments {
+		if comment.Text == text {
+			body, err := json.Marshal(comment)
+			return body, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (c Client) findAddedLabel(projectID int, storyID int, name string) ([]byte, bool, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/projects/%d/stories/%d/labels", c.TrackerAPI, projectID, storyID), nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, newError(resp)
+	}
+
+	var labels []Label
+	if err := json.NewDecoder(resp.Body).Decode(&labels); err != nil {
+		return nil, false, err
+	}
+
+	for _, label := range labels {
+		if label.Name == name {
+			body, err := json.Marshal(label)
+			return body, err == nil, err
+		}
+	}
+	return nil, false, nil
+}
@@ -317,6 +317,108 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("UpdateStory", func() {
+		var (
+			ts     *httptest.Server
+			client tracker.Client
+		)
+
+		BeforeEach(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-TrackerToken") != "my-tracker-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+
+				if r.Method == "PUT" && r.URL.Path == "/projects/99/stories/1098" {
+					body, err := ioutil.ReadAll(r.Body)
+					if err != nil {
+						w.WriteHeader(http.StatusInternalServerError)
+						w.Write([]byte(err.Error()))
+					}
+
+					if string(body) == "{\"current_state\":\"accepted\"}\n" {
+						w.Write([]byte(`{"id": 1098, "name": "my story", "current_state": "accepted"}`))
+						return
+					}
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write(body)
+					return
+				}
+
+				w.WriteHeader(http.StatusTeapot)
+			}))
+
+			client = tracker.Client{
+				APIToken:   "my-tracker-token",
+				TrackerAPI: ts.URL,
+			}
+		})
+
+		It("only sends the fields being changed", func() {
+			story, err := client.UpdateStory(99, 1098, tracker.Story{CurrentState: "accepted"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(story).To(Equal(tracker.Story{
+				ID:           1098,
+				Name:         "my story",
+				CurrentState: "accepted",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the return code is not a 200", func() {
+				_, err := client.UpdateStory(99, 1, tracker.Story{})
+				Expect(err).To(MatchError("418 I'm a teapot - "))
+			})
+
+			It("returns an error when url is malformed", func() {
+				client := tracker.Client{
+					TrackerAPI: "%%",
+				}
+
+				_, err := client.UpdateStory(99, 1098, tracker.Story{})
+				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
+			})
+		})
+	})
+
+	Describe("AddLabel", func() {
+		var (
+			ts     *httptest.Server
+			client tracker.Client
+		)
+
+		BeforeEach(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" && r.URL.Path == "/projects/99/stories/1098/labels" && r.Header.Get("X-TrackerToken") == "my-tracker-token" {
+					body, _ := ioutil.ReadAll(r.Body)
+					if string(body) == "{\"name\":\"flaky\"}\n" {
+						w.Write([]byte(`{"id": 5, "name": "flaky"}`))
+						return
+					}
+				}
+
+				w.WriteHeader(http.StatusTeapot)
+			}))
+
+			client = tracker.Client{
+				APIToken:   "my-tracker-token",
+				TrackerAPI: ts.URL,
+			}
+		})
+
+		AfterEach(func() {
+			ts.Close()
+		})
+
+		It("adds the label to the story", func() {
+			Expect(client.AddLabel(99, 1098, "flaky")).To(Succeed())
+		})
+
+		It("returns an error when the return code is not a 200", func() {
+			Expect(client.AddLabel(99, 1, "flaky")).To(MatchError("418 I'm a teapot - "))
+		})
+	})
+
 	Describe("AddComment", func() {
 		var (
 			ts     *httptest.Server
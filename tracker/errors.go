@@ -0,0 +1,84 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Error is a non-2xx response from the Tracker API. When the body is the v5
+// JSON error envelope its fields are decoded alongside the raw body.
+type Error struct {
+	StatusCode       int               `json:"-"`
+	Status           string            `json:"-"`
+	Body             string            `json:"-"`
+	Code             string            `json:"code"`
+	Kind             string            `json:"kind"`
+	Message          string            `json:"error"`
+	Requirement      string            `json:"requirement"`
+	GeneralProblem   string            `json:"general_problem"`
+	PossibleFix      string            `json:"possible_fix"`
+	ValidationErrors []ValidationError `json:"validation_errors"`
+}
+
+type ValidationError struct {
+	Field   string `json:"field"`
+	Problem string `json:"problem"`
+}
+
+func newError(resp *http.Response) *Error {
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+	json.Unmarshal(body, e)
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s - %s", e.Status, e.Body)
+	}
+
+	details := []string{e.Message}
+	if e.GeneralProblem != "" {
+		details = append(details, e.GeneralProblem)
+	}
+	for _, v := range e.ValidationErrors {
+		details = append(details, fmt.Sprintf("%s %s", v.Field, v.Problem))
+	}
+	return fmt.Sprintf("%s - %s: %s", e.Status, e.Code, strings.Join(details, "; "))
+}
+
+// Unauthorized reports whether Tracker rejected the API token.
+func (e *Error) Unauthorized() bool {
+	switch e.Code {
+	case "invalid_authentication", "unauthenticated":
+		return true
+	}
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// Forbidden reports whether the token is valid but lacks access to the
+// project, which is as unrecoverable as a bad token.
+func (e *Error) Forbidden() bool {
+	return e.Code == "unauthorized_operation" || e.StatusCode == http.StatusForbidden
+}
+
+func (e *Error) NotFound() bool {
+	return e.Code == "unfound_resource" || e.StatusCode == http.StatusNotFound
+}
+
+func (e *Error) Invalid() bool {
+	return e.Code == "invalid_parameter" || len(e.ValidationErrors) > 0
+}
+
+// Temporary reports whether the same request may succeed if made later.
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
@@ -0,0 +1,97 @@
+package tracker_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error", func() {
+	var (
+		ts     *httptest.Server
+		status int
+		body   string
+		client tracker.Client
+	)
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+
+		client = tracker.Client{
+			TrackerAPI: ts.URL,
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("decodes the Tracker error envelope", func() {
+		status = http.StatusBadRequest
+		body = `{
+			"code": "invalid_parameter",
+			"kind": "error",
+			"error": "One or more request parameters was missing or invalid.",
+			"general_problem": "this endpoint requires at least one of the following parameters: name",
+			"validation_errors": [{"field": "name", "problem": "can't be blank"}]
+		}`
+
+		_, err := client.CreateStory(99, tracker.Story{})
+
+		var trackerErr *tracker.Error
+		Expect(errors.As(err, &trackerErr)).To(BeTrue())
+		Expect(trackerErr.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(trackerErr.Code).To(Equal("invalid_parameter"))
+		Expect(trackerErr.Kind).To(Equal("error"))
+		Expect(trackerErr.ValidationErrors).To(Equal([]tracker.ValidationError{
+			{Field: "name", Problem: "can't be blank"},
+		}))
+		Expect(trackerErr.Invalid()).To(BeTrue())
+		Expect(trackerErr.Temporary()).To(BeFalse())
+		Expect(err).To(MatchError("400 Bad Request - invalid_parameter: One or more request parameters was missing or invalid.; this endpoint requires at least one of the following parameters: name; name can't be blank"))
+	})
+
+	It("identifies a rejected API token", func() {
+		status = http.StatusForbidden
+		body = `{"code": "invalid_authentication", "kind": "error", "error": "Invalid authentication credentials were presented."}`
+
+		_, err := client.Stories(99, "")
+
+		var trackerErr *tracker.Error
+		Expect(errors.As(err, &trackerErr)).To(BeTrue())
+		Expect(trackerErr.Unauthorized()).To(BeTrue())
+	})
+
+	It("identifies a missing project", func() {
+		status = http.StatusNotFound
+		body = `{"code": "unfound_resource", "kind": "error", "error": "The object you tried to access could not be found."}`
+
+		_, err := client.ListComments(99, 101)
+
+		var trackerErr *tracker.Error
+		Expect(errors.As(err, &trackerErr)).To(BeTrue())
+		Expect(trackerErr.NotFound()).To(BeTrue())
+		Expect(trackerErr.Unauthorized()).To(BeFalse())
+	})
+
+	It("keeps the raw body when the response is not an envelope", func() {
+		status = http.StatusServiceUnavailable
+		body = "try again later"
+
+		err := client.AddComment(99, 101, "comment")
+
+		var trackerErr *tracker.Error
+		Expect(errors.As(err, &trackerErr)).To(BeTrue())
+		Expect(trackerErr.Temporary()).To(BeTrue())
+		Expect(trackerErr.Body).To(Equal("try again later"))
+		Expect(err).To(MatchError("503 Service Unavailable - try again later"))
+	})
+})
@@ -0,0 +1,175 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const defaultPageSize = 100
+
+// pager walks a Tracker list endpoint one page at a time using the
+// limit/offset query parameters and the X-Tracker-Pagination-* response
+// headers. Endpoints that do not paginate are treated as a single page.
+type pager struct {
+	client Client
+	url    string
+	query  url.Values
+	offset int
+	done   bool
+	err    error
+}
+
+func newPager(client Client, rawURL string, query url.Values) *pager {
+	if query == nil {
+		query = url.Values{}
+	}
+	return &pager{client: client, url: rawURL, query: query}
+}
+
+func (p *pager) pageSize() int {
+	if p.client.PageSize > 0 {
+		return p.client.PageSize
+	}
+	return defaultPageSize
+}
+
+func (p *pager) next() ([]json.RawMessage, bool) {
+	if p.done || p.err != nil {
+		return nil, false
+	}
+
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+
+	q := req.URL.Query()
+	for key, values := range p.query {
+		q[key] = values
+	}
+	q.Set("limit", strconv.Itoa(p.pageSize()))
+	q.Set("offset", strconv.Itoa(p.offset))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := p.client.doRequest(req)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		p.err = newError(resp)
+		return nil, false
+	}
+
+	var items []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		p.err = err
+		return nil, false
+	}
+
+	total, err := strconv.Atoi(resp.Header.Get("X-Tracker-Pagination-Total"))
+	if err != nil {
+		p.done = true
+	} else {
+		p.offset += len(items)
+		p.done = len(items) == 0 || p.offset >= total
+	}
+
+	return items, true
+}
+
+func (p *pager) decode(item json.RawMessage, v interface{}) bool {
+	if err := json.Unmarshal(item, v); err != nil {
+		p.err = err
+		return false
+	}
+	return true
+}
+
+type StoryIterator struct {
+	pages   *pager
+	buffer  []json.RawMessage
+	current Story
+}
+
+// StoryIterator returns an iterator over every story matching filter,
+// fetching further pages from Tracker as the caller advances.
+func (c Client) StoryIterator(projectID int, filter string) *StoryIterator {
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	return &StoryIterator{
+		pages: newPager(c, fmt.Sprintf("%s/projects/%d/stories", c.TrackerAPI, projectID), query),
+	}
+}
+
+func (it *StoryIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		page, ok := it.pages.next()
+		if !ok {
+			return false
+		}
+		it.buffer = page
+	}
+
+	var story Story
+	if !it.pages.decode(it.buffer[0], &story) {
+		return false
+	}
+	it.current, it.buffer = story, it.buffer[1:]
+	return true
+}
+
+func (it *StoryIterator) Story() Story {
+	return it.current
+}
+
+func (it *StoryIterator) Err() error {
+	return it.pages.err
+}
+
+type CommentIterator struct {
+	pages   *pager
+	buffer  []json.RawMessage
+	current Comment
+}
+
+// CommentIterator returns an iterator over every comment on a story,
+// fetching further pages from Tracker as the caller advances.
+func (c Client) CommentIterator(projectID int, storyID int) *CommentIterator {
+	return &CommentIterator{
+		pages: newPager(c, fmt.Sprintf("%s/projects/%d/stories/%d/comments", c.TrackerAPI, projectID, storyID), nil),
+	}
+}
+
+func (it *CommentIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		page, ok := it.pages.next()
+		if !ok {
+			return false
+		}
+		it.buffer = page
+	}
+
+	var comment Comment
+	if !it.pages.decode(it.buffer[0], &comment) {
+		return false
+	}
+	it.current, it.buffer = comment, it.buffer[1:]
+	return true
+}
+
+func (it *CommentIterator) Comment() Comment {
+	return it.current
+}
+
+func (it *CommentIterator) Err() error {
+	return it.pages.err
+}
@@ -0,0 +1,143 @@
+package tracker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func paginate(w http.ResponseWriter, r *http.Request, items []interface{}) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	page := items[offset:end]
+
+	w.Header().Set("X-Tracker-Pagination-Total", strconv.Itoa(len(items)))
+	w.Header().Set("X-Tracker-Pagination-Offset", strconv.Itoa(offset))
+	w.Header().Set("X-Tracker-Pagination-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Tracker-Pagination-Returned", strconv.Itoa(len(page)))
+	json.NewEncoder(w).Encode(page)
+}
+
+var _ = Describe("Pagination", func() {
+	var (
+		ts       *httptest.Server
+		client   tracker.Client
+		requests []string
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+
+		allStories := []interface{}{}
+		for i := 1; i <= 5; i++ {
+			allStories = append(allStories, tracker.Story{ID: i, Name: fmt.Sprintf("story %d", i)})
+		}
+		allComments := []interface{}{}
+		for i := 1; i <= 3; i++ {
+			allComments = append(allComments, tracker.Comment{Text: fmt.Sprintf("comment %d", i)})
+		}
+
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+
+			switch r.URL.Path {
+			case "/projects/99/stories":
+				Expect(r.URL.Query().Get("filter")).To(Equal("label:\"broken build\""))
+				paginate(w, r, allStories)
+			case "/projects/99/stories/101/comments":
+				paginate(w, r, allComments)
+			default:
+				w.WriteHeader(http.StatusTeapot)
+			}
+		}))
+
+		client = tracker.Client{
+			TrackerAPI: ts.URL,
+			PageSize:   2,
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("Stories", func() {
+		It("follows the pagination headers until every story has been read", func() {
+			stories, err := client.Stories(99, `label:"broken build"`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stories).To(HaveLen(5))
+			Expect(stories[0].ID).To(Equal(1))
+			Expect(stories[4].ID).To(Equal(5))
+			Expect(requests).To(Equal([]string{
+				"filter=label%3A%22broken+build%22&limit=2&offset=0",
+				"filter=label%3A%22broken+build%22&limit=2&offset=2",
+				"filter=label%3A%22broken+build%22&limit=2&offset=4",
+			}))
+		})
+	})
+
+	Describe("StoryIterator", func() {
+		It("only requests further pages as the caller advances", func() {
+			it := client.StoryIterator(99, `label:"broken build"`)
+
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Story().Name).To(Equal("story 1"))
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Story().Name).To(Equal("story 2"))
+			Expect(requests).To(HaveLen(1))
+
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Story().Name).To(Equal("story 3"))
+			Expect(requests).To(HaveLen(2))
+			Expect(it.Err()).NotTo(HaveOccurred())
+		})
+
+		It("stops and reports the error when a page fails", func() {
+			client.TrackerAPI = ts.URL + "/missing"
+
+			it := client.StoryIterator(99, "")
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).To(MatchError("418 I'm a teapot - "))
+		})
+	})
+
+	Describe("ListComments", func() {
+		It("follows the pagination headers until every comment has been read", func() {
+			comments, err := client.ListComments(99, 101)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(comments).To(Equal([]tracker.Comment{
+				{Text: "comment 1"},
+				{Text: "comment 2"},
+				{Text: "comment 3"},
+			}))
+			Expect(requests).To(HaveLen(2))
+		})
+	})
+
+	Describe("CommentIterator", func() {
+		It("yields every comment across pages", func() {
+			it := client.CommentIterator(99, 101)
+
+			texts := []string{}
+			for it.Next() {
+				texts = append(texts, it.Comment().Text)
+			}
+			Expect(it.Err()).NotTo(HaveOccurred())
+			Expect(texts).To(Equal([]string{"comment 1", "comment 2", "comment 3"}))
+		})
+	})
+})
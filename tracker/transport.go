@@ -0,0 +1,280 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HTTPConfig struct {
+	Timeout           time.Duration
+	MaxRetries        int
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func DefaultHTTPConfig() HTTPConfig {
+	return HTTPConfig{
+		Timeout:           30 * time.Second,
+		MaxRetries:        4,
+		MinBackoff:        500 * time.Millisecond,
+		MaxBackoff:        30 * time.Second,
+		RequestsPerSecond: 5,
+		Burst:             10,
+	}
+}
+
+// NewHTTPClient builds an http.Client whose requests are rate limited and
+// retried according to config. Timeout bounds each individual attempt,
+// including reading its response body.
+func NewHTTPClient(config HTTPConfig) *http.Client {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = config.Timeout
+
+	var limiter *RateLimiter
+	if config.RequestsPerSecond > 0 {
+		limiter = NewRateLimiter(config.RequestsPerSecond, config.Burst)
+	}
+
+	return &http.Client{
+		Transport: &RetryTransport{
+			Base:       base,
+			Timeout:    config.Timeout,
+			Limiter:    limiter,
+			MaxRetries: config.MaxRetries,
+			MinBackoff: config.MinBackoff,
+			MaxBackoff: config.MaxBackoff,
+		},
+	}
+}
+
+// ExistenceCheck reports whether a non-idempotent request has already taken
+// effect on the server. When it has, body is returned to the caller in place
+// of the response that was lost.
+type ExistenceCheck func() (body []byte, found bool, err error)
+
+type existenceCheckKey struct{}
+
+// WithExistenceCheck allows RetryTransport to retry a POST after asking check
+// whether the first attempt was applied. POSTs without a check are only
+// retried when the server explicitly throttled them.
+func WithExistenceCheck(req *http.Request, check ExistenceCheck) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), existenceCheckKey{}, check))
+}
+
+type RetryTransport struct {
+	Base       http.RoundTripper
+	Timeout    time.Duration
+	Limiter    *RateLimiter
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	check, _ := req.Context().Value(existenceCheckKey{}).(ExistenceCheck)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Limiter != nil {
+			t.Limiter.Wait()
+		}
+
+		resp, err := t.attempt(attemptReq)
+		if !retryable(resp, err) || attempt >= t.MaxRetries {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		throttled := resp != nil && resp.StatusCode == http.StatusTooManyRequests
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if !idempotent(req.Method) && !throttled {
+			if check == nil {
+				return nil, errors.New("tracker: refusing to retry " + req.Method + " " + req.URL.Path + " without an existence check")
+			}
+
+			time.Sleep(wait)
+			body, found, err := check()
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				return alreadyApplied(req, body), nil
+			}
+			continue
+		}
+
+		time.Sleep(wait)
+	}
+}
+
+// attempt sends req once, cancelling it when Timeout passes before its body
+// has been read and closed.
+func (t *RetryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.Timeout <= 0 {
+		return t.base().RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.Timeout)
+	resp, err := t.base().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// backoff is the Retry-After of resp or else an exponential backoff, never
+// longer than MaxBackoff.
+func (t *RetryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if t.MaxBackoff > 0 && wait > t.MaxBackoff {
+				wait = t.MaxBackoff
+			}
+			if wait < 0 {
+				wait = 0
+			}
+			return wait
+		}
+	}
+
+	wait := t.MinBackoff << uint(attempt)
+	if t.MaxBackoff > 0 && (wait > t.MaxBackoff || wait <= 0) {
+		wait = t.MaxBackoff
+	}
+	return wait
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+func alreadyApplied(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// RateLimiter is a token bucket shared by every request made with one
+// Tracker API token.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		wait := time.Duration((1 - l.tokens) * float64(l.interval))
+		time.Sleep(wait)
+		l.last = time.Now()
+		l.tokens = 1
+	}
+	l.tokens--
+}
@@ -0,0 +1,245 @@
+package tracker_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jaresty/concourse-tracker-bot/tracker"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryTransport", func() {
+	var (
+		ts       *httptest.Server
+		handler  http.HandlerFunc
+		client   tracker.Client
+		attempts map[string]int
+	)
+
+	BeforeEach(func() {
+		attempts = map[string]int{}
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts[r.Method+" "+r.URL.Path]++
+			handler(w, r)
+		}))
+
+		client = tracker.Client{
+			TrackerAPI: ts.URL,
+			HTTPClient: tracker.NewHTTPClient(tracker.HTTPConfig{
+				Timeout:    time.Second,
+				MaxRetries: 2,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 5 * time.Millisecond,
+			}),
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Context("idempotent requests", func() {
+		It("retries server errors until the request succeeds", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if attempts["GET /projects/99/stories"] < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`[{"id": 1, "name": "story"}]`))
+			}
+
+			stories, err := client.Stories(99, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stories).To(HaveLen(1))
+			Expect(attempts["GET /projects/99/stories"]).To(Equal(3))
+		})
+
+		It("gives up after the configured number of retries", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte("upstream"))
+			}
+
+			_, err := client.Stories(99, "")
+			Expect(err).To(MatchError("502 Bad Gateway - upstream"))
+			Expect(attempts["GET /projects/99/stories"]).To(Equal(3))
+		})
+
+		It("does not retry client errors", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+
+			_, err := client.Stories(99, "")
+			Expect(err).To(HaveOccurred())
+			Expect(attempts["GET /projects/99/stories"]).To(Equal(1))
+		})
+
+		It("waits for the duration in the Retry-After header", func() {
+			client.HTTPClient = tracker.NewHTTPClient(tracker.HTTPConfig{
+				Timeout:    time.Second,
+				MaxRetries: 2,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 2 * time.Second,
+			})
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if attempts["GET /projects/99/stories"] == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`[]`))
+			}
+
+			start := time.Now()
+			_, err := client.Stories(99, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+	})
+
+	It("waits no longer than the maximum backoff whatever the Retry-After header says", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			if attempts["GET /projects/99/stories"] == 1 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`[]`))
+		}
+
+		start := time.Now()
+		_, err := client.Stories(99, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("times out a response whose body stalls", func() {
+		client.HTTPClient = tracker.NewHTTPClient(tracker.HTTPConfig{Timeout: 50 * time.Millisecond})
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"id": 1,`))
+			w.(http.Flusher).Flush()
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte(`"name": "story"}]`))
+		}
+
+		_, err := client.Stories(99, "")
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+	})
+
+	Context("non-idempotent requests", func() {
+		It("returns the existing story instead of creating it twice", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					w.WriteHeader(http.StatusInternalServerError)
+				case "GET":
+					if r.URL.Query().Get("filter") != `name:"my story" -state:accepted` {
+						w.Write([]byte(`[]`))
+						return
+					}
+					w.Write([]byte(`[{"id": 1098, "name": "my story"}]`))
+				}
+			}
+
+			story, err := client.CreateStory(99, tracker.Story{Name: "my story"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(story.ID).To(Equal(1098))
+			Expect(attempts["POST /projects/99/stories"]).To(Equal(1))
+		})
+
+		It("escapes quotes in the title it looks the story up by", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					w.WriteHeader(http.StatusInternalServerError)
+				case "GET":
+					Expect(r.URL.Query().Get("filter")).To(Equal(`name:"say \"hi\" has failed" -state:accepted`))
+					w.Write([]byte(`[{"id": 1098, "name": "say \"hi\" has failed"}]`))
+				}
+			}
+
+			story, err := client.CreateStory(99, tracker.Story{Name: `say "hi" has failed`})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(story.ID).To(Equal(1098))
+		})
+
+		It("retries the comment when it was not recorded", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					if attempts["POST /projects/99/stories/101/comments"] == 1 {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					w.Write([]byte(`{"text": "my comment"}`))
+				case "GET":
+					w.Write([]byte(`[{"text": "another comment"}]`))
+				}
+			}
+
+			err := client.AddComment(99, 101, "my comment")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts["POST /projects/99/stories/101/comments"]).To(Equal(2))
+			Expect(attempts["GET /projects/99/stories/101/comments"]).To(Equal(1))
+		})
+
+		It("does not add a label twice when the first attempt was applied", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					w.WriteHeader(http.StatusBadGateway)
+				case "GET":
+					w.Write([]byte(`[{"id": 5, "name": "flaky"}, {"id": 6, "name": "broken-build"}]`))
+				}
+			}
+
+			err := client.AddLabel(99, 101, "broken-build")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts["POST /projects/99/stories/101/labels"]).To(Equal(1))
+			Expect(attempts["GET /projects/99/stories/101/labels"]).To(Equal(1))
+		})
+
+		It("retries throttled requests without checking", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if attempts["POST /projects/99/stories/101/comments"] == 1 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{"text": "my comment"}`))
+			}
+
+			err := client.AddComment(99, 101, "my comment")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts["POST /projects/99/stories/101/comments"]).To(Equal(2))
+			Expect(attempts["GET /projects/99/stories/101/comments"]).To(Equal(0))
+		})
+
+		It("refuses to blindly retry a request without an existence check", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			_, err := client.HTTPClient.Post(ts.URL+"/anything", "application/json", bytes.NewBufferString("{}"))
+			Expect(err).To(MatchError(ContainSubstring("refusing to retry POST /anything")))
+			Expect(attempts["POST /anything"]).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("RateLimiter", func() {
+	It("spaces out requests beyond the burst", func() {
+		limiter := tracker.NewRateLimiter(20, 2)
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			limiter.Wait()
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+	})
+})
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/jaresty/concourse-tracker-bot/config"
+	"github.com/jaresty/concourse-tracker-bot/parser"
+)
+
+func validateCommand(args []string) error {
+	var opts options
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	opts.register(flags)
+	flags.Parse(args)
+
+	autoGroup, err := opts.autoGroup()
+	if err != nil {
+		return err
+	}
+	if opts.groupConfigFile == "" && !autoGroup {
+		return errors.New("-group-config-file is required")
+	}
+	groups := map[string][]string{}
+	if opts.groupConfigFile != "" {
+		if groups, err = parser.Read(opts.groupConfigSource()); err != nil {
+			return fmt.Errorf("%s: %s", opts.groupConfigFile, err)
+		}
+		if err := parser.Validate(groups); err != nil {
+			return fmt.Errorf("%s: %s", opts.groupConfigFile, err)
+		}
+	}
+
+	if _, err := config.Load(opts.configFile); err != nil {
+		return fmt.Errorf("%s: %s", opts.configFile, err)
+	}
+
+	switch opts.backendName {
+	case "", "tracker", "github":
+	case "jira":
+		if _, err := loadJiraConfig(opts.jiraConfigFile); err != nil {
+			return fmt.Errorf("%s: %s", opts.jiraConfigFile, err)
+		}
+	default:
+		return fmt.Errorf("unknown issue backend %q", opts.backendName)
+	}
+
+	fmt.Printf("%d groups, config ok\n", len(groups))
+	return nil
+}